@@ -0,0 +1,106 @@
+// Command json2vcl generates VCL of backends, directors, ACLs and tables from JSON or YAML.
+//
+// Usage:
+//
+//	json2vcl [-format json|yaml] [-dialect fastly|varnish] [file]
+//
+// The document has the same schema as the output of vcl2json.
+// The format is detected by the file extension unless -format is set, and the input is read from stdin when the file is omitted.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/printer"
+	"github.com/KeisukeYamashita/go-vcl/internal/vcljson"
+)
+
+func main() {
+	format := flag.String("format", "", "input format, json or yaml")
+	dialectName := flag.String("dialect", dialect.Default.Name, "dialect of VCL, one of "+strings.Join(dialect.Names(), ", "))
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	d, ok := dialect.Lookup(*dialectName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown dialect %q\n", *dialectName)
+		os.Exit(2)
+	}
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	filename := flag.Arg(0)
+	if *format == "" {
+		*format = detectFormat(filename)
+	}
+
+	if *format != "json" && *format != "yaml" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	b, err := readInput(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	doc := &vcljson.Document{}
+	if *format == "yaml" {
+		err = yaml.Unmarshal(b, doc)
+	} else {
+		err = json.Unmarshal(b, doc)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	program, errs := vcljson.Generate(doc, d)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+
+	if err := printer.Fprint(os.Stdout, program); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// detectFormat returns the format by the extension of the file, json by default
+func detectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+
+	return "json"
+}
+
+// readInput reads the file, or stdin if the filename is empty
+func readInput(filename string) ([]byte, error) {
+	if filename == "" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	return ioutil.ReadFile(filename)
+}
@@ -0,0 +1,28 @@
+// Command vcl-lsp is a language server of VCL which communicates over stdio.
+//
+// Usage:
+//
+//	vcl-lsp
+//
+// The dialect is "fastly" by default and can be changed by the initialization option `{"dialect": "varnish"}`.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lsp"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s\n\nThe server reads the requests from stdin and writes the responses to stdout.\n", os.Args[0])
+	}
+	flag.Parse()
+
+	if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
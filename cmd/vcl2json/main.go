@@ -0,0 +1,105 @@
+// Command vcl2json converts a VCL file to JSON or YAML.
+//
+// Usage:
+//
+//	vcl2json [-ast] [-format json|yaml] [file.vcl]
+//
+// By default it prints the declarative part which is backends, directors, ACLs and tables.
+// With -ast it prints the full AST. The input is read from stdin when the file is omitted.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/vcljson"
+)
+
+func main() {
+	full := flag.Bool("ast", false, "print the full AST instead of the declarations")
+	format := flag.String("format", "json", "output format, json or yaml")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file.vcl]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *format != "json" && *format != "yaml" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	filename, b, err := readInput(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	p := parser.NewParser(lexer.NewLexer(string(b)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+		}
+		os.Exit(1)
+	}
+
+	var v interface{}
+	if *full {
+		v = vcljson.Program(program)
+	} else {
+		doc, errs := vcljson.Declarations(program)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+			}
+			os.Exit(1)
+		}
+		v = doc
+	}
+
+	if err := encode(os.Stdout, v, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// readInput reads the file, or stdin if the filename is empty
+func readInput(filename string) (string, []byte, error) {
+	if filename == "" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		return "<stdin>", b, err
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	return filename, b, err
+}
+
+func encode(w io.Writer, v interface{}, format string) error {
+	if format == "yaml" {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
@@ -0,0 +1,124 @@
+// Command vcldiff prints the semantic changes between two VCL files.
+//
+// Usage:
+//
+//	vcldiff [-json] old.vcl new.vcl
+//
+// Formatting and comments are ignored. The exit status is 0 if there are no changes, 1 if there are changes and 2 on errors.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/vcldiff"
+)
+
+// change is the JSON representation of the change
+type change struct {
+	Kind        vcldiff.Kind `json:"kind"`
+	Declaration string       `json:"declaration"`
+	Member      string       `json:"member,omitempty"`
+	Old         string       `json:"old,omitempty"`
+	New         string       `json:"new,omitempty"`
+	OldPos      string       `json:"old_pos,omitempty"`
+	NewPos      string       `json:"new_pos,omitempty"`
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the changes as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] old.vcl new.vcl\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	old, ok := parseFile(flag.Arg(0))
+	if !ok {
+		os.Exit(2)
+	}
+
+	new, ok := parseFile(flag.Arg(1))
+	if !ok {
+		os.Exit(2)
+	}
+
+	changes, err := vcldiff.Diff(old, new)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *asJSON {
+		printJSON(changes)
+	} else {
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseFile(filename string) (*ast.Program, bool) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+
+	p := parser.NewParser(lexer.NewLexer(string(b)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+		}
+		return nil, false
+	}
+
+	return program, true
+}
+
+func printJSON(changes []*vcldiff.Change) {
+	out := make([]*change, 0, len(changes))
+	for _, c := range changes {
+		jc := &change{
+			Kind:        c.Kind,
+			Declaration: c.Declaration,
+			Member:      c.Member,
+			Old:         c.Old,
+			New:         c.New,
+		}
+
+		if c.OldPos.IsValid() {
+			jc.OldPos = c.OldPos.String()
+		}
+
+		if c.NewPos.IsValid() {
+			jc.NewPos = c.NewPos.String()
+		}
+
+		out = append(out, jc)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,106 @@
+// Command vcllint reports problems of VCL files.
+//
+// Usage:
+//
+//	vcllint [-dialect fastly|varnish] [-disable rule-id,...] file.vcl...
+//
+// Diagnostics can be suppressed by the comment `# vcllint:ignore rule-id` on the same line or the previous line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/lint"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func main() {
+	disable := flag.String("disable", "", "comma separated rule IDs to disable")
+	list := flag.Bool("list", false, "list the rule IDs")
+	dialectName := flag.String("dialect", dialect.Default.Name, "VCL dialect, one of "+strings.Join(dialect.Names(), ", "))
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file.vcl...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	rules := enabledRules(*disable)
+	if *list {
+		for _, rule := range rules {
+			fmt.Println(rule.ID())
+		}
+		return
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	d, ok := dialect.Lookup(*dialectName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown dialect %q\n", *dialectName)
+		os.Exit(2)
+	}
+
+	linter := lint.NewLinter(rules...)
+	linter.Dialect = d
+
+	var failed bool
+	for _, filename := range flag.Args() {
+		if !lintFile(linter, filename) {
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func enabledRules(disable string) []lint.Rule {
+	disabled := map[string]bool{}
+	for _, id := range strings.Split(disable, ",") {
+		disabled[strings.TrimSpace(id)] = true
+	}
+
+	rules := []lint.Rule{}
+	for _, rule := range lint.DefaultRules() {
+		if !disabled[rule.ID()] {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// lintFile prints the problems of the file and reports whether the file has no problem
+func lintFile(linter *lint.Linter, filename string) bool {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	p := parser.NewParser(lexer.NewLexer(string(b)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+		}
+		return false
+	}
+
+	diagnostics := linter.Lint(program)
+	for _, d := range diagnostics {
+		fmt.Printf("%s:%s\n", filename, d)
+	}
+
+	return len(diagnostics) == 0
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 
 	"github.com/KeisukeYamashita/go-vcl/vcl"
@@ -20,16 +19,11 @@ type ACL struct {
 }
 
 func main() {
-	dat, err := ioutil.ReadFile("./example/vcl.vcl")
+	r, err := vcl.DecodeFile[Root]("./example/vcl.vcl")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	r := &Root{}
-	if errs := vcl.Decode(dat, r); len(errs) > 0 {
-		log.Fatal(errs)
-	}
-
 	fmt.Println(r.ACls)
 	fmt.Println(r.ACls[0].Endpoints)
 }
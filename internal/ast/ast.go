@@ -7,6 +7,15 @@ type Program struct {
 	Statements []Statement
 }
 
+// TokenLiteral returns the literal of the first token
+func (p *Program) TokenLiteral() string {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].TokenLiteral()
+	}
+
+	return ""
+}
+
 // Node ...
 type Node interface {
 	TokenLiteral() string
@@ -78,6 +87,7 @@ func (exp *BlockExpression) TokenLiteral() string {
 type BlockStatement struct {
 	Token      token.Token // token.LBRACE
 	Statements []Statement
+	Rbrace     token.Position // position of the closing brace
 }
 
 func (bs *BlockStatement) statementNode() {}
@@ -109,10 +119,34 @@ func (as *AssignStatement) TokenLiteral() string {
 	return as.Token.Literal
 }
 
+// SetStatement holds the variable for the Identifier and its value such as `set req.http.X = "foo";`
+type SetStatement struct {
+	Token    token.Token // token.SET
+	Name     *Identifier
+	Operator string // "=" or a compound operator such as "+="
+	Value    Expression
+}
+
+func (ss *SetStatement) statementNode() {}
+func (ss *SetStatement) TokenLiteral() string {
+	return ss.Token.Literal
+}
+
+// UnsetStatement holds the variable for the Identifier such as `unset req.http.X;`
+type UnsetStatement struct {
+	Token token.Token // token.UNSET
+	Name  *Identifier
+}
+
+func (us *UnsetStatement) statementNode() {}
+func (us *UnsetStatement) TokenLiteral() string {
+	return us.Token.Literal
+}
+
 // ReturnStatement holds the Name for the Identifier and its value
 type ReturnStatement struct {
 	Token       token.Token // token.RETURN
-	ReturnValue Expression
+	ReturnValue Expression  // nil for `return;` without the action
 }
 
 func (as *ReturnStatement) statementNode() {}
@@ -152,6 +186,18 @@ func (as *ExpressionStatement) TokenLiteral() string {
 	return as.Token.Literal
 }
 
+// CallExpression is a function call such as `table.lookup(my_table, req.url)`
+type CallExpression struct {
+	Token     token.Token // token.LPAREN
+	Function  Expression
+	Arguments []Expression
+}
+
+func (exp *CallExpression) expressionNode() {}
+func (exp *CallExpression) TokenLiteral() string {
+	return exp.Token.Literal
+}
+
 // Identifier ...
 type Identifier struct {
 	Token token.Token // token.IDENT
@@ -216,3 +262,35 @@ func (i *PercentageLiteral) expressionNode() {}
 func (i *PercentageLiteral) TokenLiteral() string {
 	return i.Token.Literal
 }
+
+// RTimeLiteral is a relative time such as 10s
+type RTimeLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (i *RTimeLiteral) expressionNode() {}
+func (i *RTimeLiteral) TokenLiteral() string {
+	return i.Token.Literal
+}
+
+// BadStatement is a placeholder for the statement which has syntax errors
+type BadStatement struct {
+	Token token.Token    // the first token of the statement
+	End   token.Position // position of the last token which is skipped
+}
+
+func (bs *BadStatement) statementNode() {}
+func (bs *BadStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+// BadExpression is a placeholder for the expression which has syntax errors
+type BadExpression struct {
+	Token token.Token // the token where the error is found
+}
+
+func (exp *BadExpression) expressionNode() {}
+func (exp *BadExpression) TokenLiteral() string {
+	return exp.Token.Literal
+}
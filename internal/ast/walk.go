@@ -0,0 +1,141 @@
+package ast
+
+// Visitor visits the nodes in Walk.
+// If Visit returns a visitor w which is not nil, the children of the node are visited with w, and then w.Visit(nil) is called.
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the node in depth-first order.
+// It starts by calling v.Visit(node), and the children which are nil such as the missing else branch are skipped.
+// The names of the statements such as req.url in `set req.url = "/";` are visited as the identifiers.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *ExpressionStatement:
+		walkExpression(v, n.Expression)
+	case *AssignStatement:
+		walkIdentifier(v, n.Name)
+		walkExpression(v, n.Value)
+	case *AssignFieldStatement:
+		walkIdentifier(v, n.Name)
+		walkExpression(v, n.Value)
+	case *SetStatement:
+		walkIdentifier(v, n.Name)
+		walkExpression(v, n.Value)
+	case *UnsetStatement:
+		walkIdentifier(v, n.Name)
+	case *ReturnStatement:
+		walkExpression(v, n.ReturnValue)
+	case *CallStatement:
+		walkExpression(v, n.CallValue)
+	case *PrefixExpression:
+		walkExpression(v, n.Right)
+	case *InfixExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+	case *IfExpression:
+		walkExpression(v, n.Condition)
+		if n.Consequence != nil {
+			Walk(v, n.Consequence)
+		}
+
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+	case *BlockExpression:
+		if n.Blocks != nil {
+			Walk(v, n.Blocks)
+		}
+	case *CallExpression:
+		walkExpression(v, n.Function)
+		for _, arg := range n.Arguments {
+			walkExpression(v, arg)
+		}
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, stmts []Statement) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			Walk(v, stmt)
+		}
+	}
+}
+
+// walkExpression skips nil expressions which are left by the parse errors
+func walkExpression(v Visitor, expr Expression) {
+	if expr != nil {
+		Walk(v, expr)
+	}
+}
+
+func walkIdentifier(v Visitor, ident *Identifier) {
+	if ident != nil {
+		Walk(v, ident)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+
+	return nil
+}
+
+// Inspect traverses the node in depth-first order by calling f(node).
+// The children of the node are not visited if f returns false, otherwise f(nil) is called after the children.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// InspectReads traverses the node like Inspect, but f is not called with the identifiers which are written by the statements
+// such as req.url in `set req.url = "/";`, so that all identifiers passed to f are read.
+// The variable of a compound set such as `set var.n += 1;` is passed because its previous value is read.
+func InspectReads(node Node, f func(Node) bool) {
+	targets := map[*Identifier]bool{}
+	Inspect(node, func(n Node) bool {
+		if ident, ok := n.(*Identifier); ok && targets[ident] {
+			return true
+		}
+
+		if target := assignTarget(n); target != nil {
+			targets[target] = true
+		}
+
+		return f(n)
+	})
+}
+
+// assignTarget returns the identifier which is written by the statement, or nil
+func assignTarget(node Node) *Identifier {
+	switch v := node.(type) {
+	case *SetStatement:
+		// compound operators such as += read the previous value
+		if v.Operator != "" && v.Operator != "=" {
+			return nil
+		}
+
+		return v.Name
+	case *UnsetStatement:
+		return v.Name
+	case *AssignStatement:
+		return v.Name
+	case *AssignFieldStatement:
+		return v.Name
+	}
+
+	return nil
+}
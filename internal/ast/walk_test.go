@@ -0,0 +1,213 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// testProgram is
+//
+//	sub vcl_recv {
+//		if (req.url ~ "^/a") {
+//			set req.http.A = "1";
+//		} else {
+//			unset req.http.B;
+//		}
+//	}
+func testProgram() *Program {
+	return &Program{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Expression: &BlockExpression{
+					Token:  token.Token{Type: token.SUBROUTINE, Literal: "sub"},
+					Labels: []string{"vcl_recv"},
+					Blocks: &BlockStatement{
+						Statements: []Statement{
+							&ExpressionStatement{
+								Expression: &IfExpression{
+									Condition: &InfixExpression{
+										Operator: "~",
+										Left:     &Identifier{Value: "req.url"},
+										Right:    &StringLiteral{Value: "^/a"},
+									},
+									Consequence: &BlockStatement{
+										Statements: []Statement{
+											&SetStatement{Name: &Identifier{Value: "req.http.A"}, Value: &StringLiteral{Value: "1"}},
+										},
+									},
+									Alternative: &BlockStatement{
+										Statements: []Statement{
+											&UnsetStatement{Name: &Identifier{Value: "req.http.B"}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func describe(node Node) string {
+	switch v := node.(type) {
+	case *Identifier:
+		return "Identifier " + v.Value
+	case *StringLiteral:
+		return "StringLiteral " + v.Value
+	}
+
+	return fmt.Sprintf("%T", node)[len("*ast."):]
+}
+
+func TestInspect(t *testing.T) {
+	testCases := map[string]struct {
+		skip     string
+		expected []string
+	}{
+		"all nodes": {
+			expected: []string{
+				"Program",
+				"ExpressionStatement",
+				"BlockExpression",
+				"BlockStatement",
+				"ExpressionStatement",
+				"IfExpression",
+				"InfixExpression",
+				"Identifier req.url",
+				"StringLiteral ^/a",
+				"BlockStatement",
+				"SetStatement",
+				"Identifier req.http.A",
+				"StringLiteral 1",
+				"BlockStatement",
+				"UnsetStatement",
+				"Identifier req.http.B",
+			},
+		},
+		"skip children": {
+			skip: "IfExpression",
+			expected: []string{
+				"Program",
+				"ExpressionStatement",
+				"BlockExpression",
+				"BlockStatement",
+				"ExpressionStatement",
+				"IfExpression",
+			},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got := []string{}
+			Inspect(testProgram(), func(node Node) bool {
+				if node == nil {
+					return false
+				}
+
+				got = append(got, describe(node))
+				return describe(node) != tc.skip
+			})
+
+			if len(got) != len(tc.expected) {
+				t.Fatalf("nodes length wrong, got:%v, want:%v", got, tc.expected)
+			}
+
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("got:%s, want:%s", got[i], tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInspectReads(t *testing.T) {
+	got := []string{}
+	InspectReads(testProgram(), func(node Node) bool {
+		if v, ok := node.(*Identifier); ok {
+			got = append(got, v.Value)
+		}
+		return true
+	})
+
+	expected := []string{"req.url"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got:%v, want:%v", got, expected)
+	}
+}
+
+func TestInspectReads_CompoundSet(t *testing.T) {
+	stmt := &SetStatement{
+		Name:     &Identifier{Value: "var.n"},
+		Operator: "+=",
+		Value:    &Identifier{Value: "var.m"},
+	}
+
+	got := []string{}
+	InspectReads(stmt, func(node Node) bool {
+		if v, ok := node.(*Identifier); ok {
+			got = append(got, v.Value)
+		}
+		return true
+	})
+
+	expected := []string{"var.n", "var.m"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got:%v, want:%v", got, expected)
+	}
+}
+
+type depthVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v *depthVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		return nil
+	}
+
+	if v.depth > *v.max {
+		*v.max = v.depth
+	}
+
+	return &depthVisitor{depth: v.depth + 1, max: v.max}
+}
+
+func TestWalk(t *testing.T) {
+	var max int
+	Walk(&depthVisitor{max: &max}, testProgram())
+
+	// Program > ExpressionStatement > BlockExpression > BlockStatement > ExpressionStatement > IfExpression > BlockStatement > SetStatement > Identifier
+	if max != 8 {
+		t.Fatalf("got:%d, want:%d", max, 8)
+	}
+}
+
+func TestWalk_NilChildren(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{Expression: &IfExpression{Condition: &Identifier{Value: "req.url"}}},
+			&SetStatement{Name: &Identifier{Value: "req.url"}},
+			&ReturnStatement{},
+		},
+	}
+
+	var count int
+	Inspect(program, func(node Node) bool {
+		if node != nil {
+			count++
+		}
+		return true
+	})
+
+	if count != 7 {
+		t.Fatalf("got:%d, want:%d", count, 7)
+	}
+}
@@ -0,0 +1,233 @@
+package astutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+)
+
+// ApplyFunc is called with the cursor of the node in Apply
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses the node in depth-first order and returns the node which may be replaced.
+//
+// pre is called for each node before the children are visited, and the children are not visited and post is not called
+// if pre returns false. post is called after the children are visited, and Apply stops if post returns false.
+// The children which are nil such as the missing else branch are also visited so that they can be replaced.
+// Either pre or post can be nil.
+//
+// The nodes can be replaced, deleted and inserted by the cursor. The nodes which are inserted or replace the current node
+// by pre are not visited, but the children of the new node are.
+func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
+	parent := &struct{ ast.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+
+	a := &application{pre: pre, post: post}
+	a.apply(parent, "Node", nil, root)
+	return
+}
+
+var abort = new(int) // sentinel of the panic which stops Apply
+
+// Cursor is the position of the node in Apply
+type Cursor struct {
+	parent ast.Node
+	name   string
+	iter   *iterator // nil if the node is not an element of a slice
+	node   ast.Node
+}
+
+// Node returns the current node
+func (c *Cursor) Node() ast.Node { return c.node }
+
+// Parent returns the parent of the current node
+func (c *Cursor) Parent() ast.Node { return c.parent }
+
+// Name returns the name of the field of the parent which has the current node such as "Consequence"
+func (c *Cursor) Name() string { return c.name }
+
+// Index returns the index of the current node in the slice such as the Statements of the block, or -1 if it is not in a slice
+func (c *Cursor) Index() int {
+	if c.iter != nil {
+		return c.iter.index
+	}
+
+	return -1
+}
+
+// field returns the field of the parent which has the current node
+func (c *Cursor) field() reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+}
+
+// Replace replaces the current node with n.
+// It panics if n cannot be set to the field such as an expression to the Statements.
+func (c *Cursor) Replace(n ast.Node) {
+	v := c.field()
+	if i := c.Index(); i >= 0 {
+		v = v.Index(i)
+	}
+
+	v.Set(value(n, v.Type()))
+	c.node = n
+}
+
+// Delete deletes the current node from the slice.
+// It panics if the current node is not in a slice.
+func (c *Cursor) Delete() {
+	i := c.Index()
+	if i < 0 {
+		panic("Delete node not contained in slice")
+	}
+
+	v := c.field()
+	l := v.Len()
+	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
+	v.Index(l - 1).Set(reflect.Zero(v.Type().Elem()))
+	v.SetLen(l - 1)
+	c.iter.step--
+}
+
+// InsertAfter inserts n after the current node in the slice.
+// It panics if the current node is not in a slice.
+func (c *Cursor) InsertAfter(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertAfter node not contained in slice")
+	}
+
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+2, l), v.Slice(i+1, l))
+	v.Index(i + 1).Set(value(n, v.Type().Elem()))
+	c.iter.step++
+}
+
+// InsertBefore inserts n before the current node in the slice.
+// It panics if the current node is not in a slice.
+func (c *Cursor) InsertBefore(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertBefore node not contained in slice")
+	}
+
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+1, l), v.Slice(i, l))
+	v.Index(i).Set(value(n, v.Type().Elem()))
+	c.iter.index++
+}
+
+// value returns the value of the node for the field of the type, which is the zero value for nil
+func value(n ast.Node, typ reflect.Type) reflect.Value {
+	if n == nil {
+		return reflect.Zero(typ)
+	}
+
+	v := reflect.ValueOf(n)
+	if !v.Type().AssignableTo(typ) {
+		panic(fmt.Sprintf("cannot use %T as %s", n, typ))
+	}
+
+	return v
+}
+
+type iterator struct {
+	index, step int
+}
+
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+	iter      iterator
+}
+
+func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.Node) {
+	// the fields such as Alternative are typed nil
+	if v := reflect.ValueOf(n); v.Kind() == reflect.Ptr && v.IsNil() {
+		n = nil
+	}
+
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	switch n := a.cursor.node.(type) {
+	case nil:
+	case *ast.Program:
+		a.applyList(n, "Statements")
+	case *ast.BlockStatement:
+		a.applyList(n, "Statements")
+	case *ast.ExpressionStatement:
+		a.apply(n, "Expression", nil, n.Expression)
+	case *ast.AssignStatement:
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Value", nil, n.Value)
+	case *ast.AssignFieldStatement:
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Value", nil, n.Value)
+	case *ast.SetStatement:
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Value", nil, n.Value)
+	case *ast.UnsetStatement:
+		a.apply(n, "Name", nil, n.Name)
+	case *ast.ReturnStatement:
+		a.apply(n, "ReturnValue", nil, n.ReturnValue)
+	case *ast.CallStatement:
+		a.apply(n, "CallValue", nil, n.CallValue)
+	case *ast.PrefixExpression:
+		a.apply(n, "Right", nil, n.Right)
+	case *ast.InfixExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+	case *ast.IfExpression:
+		a.apply(n, "Condition", nil, n.Condition)
+		a.apply(n, "Consequence", nil, n.Consequence)
+		a.apply(n, "Alternative", nil, n.Alternative)
+	case *ast.BlockExpression:
+		a.apply(n, "Blocks", nil, n.Blocks)
+	case *ast.CallExpression:
+		a.apply(n, "Function", nil, n.Function)
+		a.applyList(n, "Arguments")
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+
+	a.cursor = saved
+}
+
+// applyList visits the elements of the slice field, which can be modified while they are visited
+func (a *application) applyList(parent ast.Node, name string) {
+	saved := a.iter
+	a.iter.index = 0
+	for {
+		// the slice is read every time because the cursor may modify it
+		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		if a.iter.index >= v.Len() {
+			break
+		}
+
+		x, _ := v.Index(a.iter.index).Interface().(ast.Node)
+		a.iter.step = 1
+		a.apply(parent, name, &a.iter, x)
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
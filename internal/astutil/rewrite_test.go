@@ -0,0 +1,246 @@
+package astutil
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/printer"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+func str(s string) *ast.StringLiteral {
+	return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: s}, Value: s}
+}
+
+func unset(name string) *ast.UnsetStatement {
+	return &ast.UnsetStatement{
+		Token: token.Token{Type: token.UNSET, Literal: "unset"},
+		Name:  &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name},
+	}
+}
+
+func TestApply(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		pre      ApplyFunc
+		post     ApplyFunc
+		expected string
+	}{
+		"replace expression": {
+			input: `sub vcl_recv {
+	set req.http.A = "a";
+}
+`,
+			pre: func(c *Cursor) bool {
+				if lit, ok := c.Node().(*ast.StringLiteral); ok && lit.Value == "a" {
+					c.Replace(str("b"))
+				}
+				return true
+			},
+			expected: `sub vcl_recv {
+	set req.http.A = "b";
+}
+`,
+		},
+		"delete statements": {
+			input: `sub vcl_recv {
+	# comment
+	set req.http.A = "a";
+	# comment
+	# comment
+}
+`,
+			pre: func(c *Cursor) bool {
+				if _, ok := c.Node().(*ast.CommentStatement); ok {
+					c.Delete()
+				}
+				return true
+			},
+			expected: `sub vcl_recv {
+	set req.http.A = "a";
+}
+`,
+		},
+		"insert statements": {
+			input: `sub vcl_recv {
+	set req.http.A = "a";
+}
+`,
+			pre: func(c *Cursor) bool {
+				if _, ok := c.Node().(*ast.SetStatement); ok {
+					c.InsertBefore(unset("req.http.B"))
+					c.InsertAfter(unset("req.http.C"))
+				}
+				return true
+			},
+			expected: `sub vcl_recv {
+	unset req.http.B;
+	set req.http.A = "a";
+	unset req.http.C;
+}
+`,
+		},
+		"replace missing else branch": {
+			input: `sub vcl_recv {
+	if (req.http.A) {
+		set req.http.B = "b";
+	}
+}
+`,
+			pre: func(c *Cursor) bool {
+				if c.Name() == "Alternative" && c.Node() == nil {
+					c.Replace(&ast.BlockStatement{Statements: []ast.Statement{unset("req.http.B")}})
+				}
+				return true
+			},
+			expected: `sub vcl_recv {
+	if (req.http.A) {
+		set req.http.B = "b";
+	} else {
+		unset req.http.B;
+	}
+}
+`,
+		},
+		"skip children": {
+			input: `sub vcl_recv {
+	if (req.http.A) {
+		set req.http.A = "a";
+	}
+	set req.http.A = "a";
+}
+`,
+			pre: func(c *Cursor) bool {
+				if _, ok := c.Node().(*ast.IfExpression); ok {
+					return false
+				}
+
+				if lit, ok := c.Node().(*ast.StringLiteral); ok && lit.Value == "a" {
+					c.Replace(str("b"))
+				}
+				return true
+			},
+			expected: `sub vcl_recv {
+	if (req.http.A) {
+		set req.http.A = "a";
+	}
+	set req.http.A = "b";
+}
+`,
+		},
+		"abort": {
+			input: `sub vcl_recv {
+	set req.http.A = "a";
+	set req.http.B = "a";
+}
+`,
+			post: func(c *Cursor) bool {
+				if lit, ok := c.Node().(*ast.StringLiteral); ok && lit.Value == "a" {
+					c.Replace(str("b"))
+					return false
+				}
+				return true
+			},
+			expected: `sub vcl_recv {
+	set req.http.A = "b";
+	set req.http.B = "a";
+}
+`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p := parser.NewParser(lexer.NewLexer(tc.input))
+			program := p.ParseProgram()
+			if errs := p.Errors(); len(errs) > 0 {
+				t.Fatalf("parse failed: %v", errs)
+			}
+
+			result := Apply(program, tc.pre, tc.post)
+
+			got, err := printer.Print(result.(*ast.Program))
+			if err != nil {
+				t.Fatalf("Print failed: %v", err)
+			}
+
+			if got != tc.expected {
+				t.Fatalf("got:%s, want:%s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestApply_Root(t *testing.T) {
+	program := &ast.Program{}
+	replaced := &ast.Program{Statements: []ast.Statement{unset("req.http.A")}}
+
+	result := Apply(program, func(c *Cursor) bool {
+		if c.Node() == program {
+			c.Replace(replaced)
+		}
+		return true
+	}, nil)
+
+	if result != replaced {
+		t.Fatalf("got:%v, want:%v", result, replaced)
+	}
+}
+
+func TestCursor_Index(t *testing.T) {
+	p := parser.NewParser(lexer.NewLexer(`sub vcl_recv { set req.http.A = "a"; unset req.http.B; }`))
+	program := p.ParseProgram()
+
+	indexes := map[string]int{}
+	Apply(program, func(c *Cursor) bool {
+		switch v := c.Node().(type) {
+		case *ast.SetStatement:
+			indexes["set"] = c.Index()
+		case *ast.UnsetStatement:
+			indexes["unset"] = c.Index()
+		case *ast.StringLiteral:
+			indexes[v.Value] = c.Index()
+		}
+		return true
+	}, nil)
+
+	expected := map[string]int{"set": 0, "unset": 1, "a": -1}
+	for k, want := range expected {
+		if indexes[k] != want {
+			t.Fatalf("index of %s wrong, got:%d, want:%d", k, indexes[k], want)
+		}
+	}
+}
+
+func TestCursor_Panic(t *testing.T) {
+	testCases := map[string]struct {
+		fn func(c *Cursor)
+	}{
+		"delete not in slice": {
+			fn: func(c *Cursor) { c.Delete() },
+		},
+		"replace with wrong type": {
+			fn: func(c *Cursor) { c.Replace(unset("req.url")) },
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("Apply did not panic")
+				}
+			}()
+
+			p := parser.NewParser(lexer.NewLexer(`sub vcl_recv { set req.http.A = "a"; }`))
+			Apply(p.ParseProgram(), func(c *Cursor) bool {
+				if _, ok := c.Node().(*ast.StringLiteral); ok {
+					tc.fn(c)
+				}
+				return true
+			}, nil)
+		})
+	}
+}
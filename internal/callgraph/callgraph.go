@@ -0,0 +1,242 @@
+package callgraph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Graph is a call graph of the subroutines
+type Graph struct {
+	Nodes []*Node // in declaration order
+
+	nodes map[string]*Node
+}
+
+// Node is a subroutine in the call graph
+type Node struct {
+	Sub     *resolve.Symbol
+	Calls   []*Edge // call sites in this subroutine
+	Callers []*Edge // call sites of this subroutine
+}
+
+// Edge is a `call` statement from the caller to the callee
+type Edge struct {
+	Caller *Node
+	Callee *Node
+	Pos    token.Position
+}
+
+// Name returns the name of the subroutine
+func (n *Node) Name() string {
+	return n.Sub.Name
+}
+
+// IsEntryPoint reports whether the subroutine is a VCL state entry point such as vcl_recv
+func (n *Node) IsEntryPoint() bool {
+	return resolve.IsBuiltinSubroutine(n.Sub.Name)
+}
+
+// Callees returns the subroutines called by this subroutine without duplication
+func (n *Node) Callees() []*Node {
+	seen := map[*Node]bool{}
+	callees := []*Node{}
+	for _, e := range n.Calls {
+		if seen[e.Callee] {
+			continue
+		}
+
+		seen[e.Callee] = true
+		callees = append(callees, e.Callee)
+	}
+
+	return callees
+}
+
+// Error is an error of the call graph
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// New builds the call graph from the symbol table.
+// The returned errors are cycles of the calls because VCL forbids recursion.
+func New(table *resolve.SymbolTable) (*Graph, []error) {
+	g := &Graph{
+		Nodes: []*Node{},
+		nodes: map[string]*Node{},
+	}
+
+	for _, sym := range table.Symbols {
+		if sym.Kind != resolve.Subroutine {
+			continue
+		}
+
+		n := &Node{
+			Sub:     sym,
+			Calls:   []*Edge{},
+			Callers: []*Edge{},
+		}
+
+		g.Nodes = append(g.Nodes, n)
+		g.nodes[sym.Name] = n
+	}
+
+	for _, ref := range table.References {
+		if ref.Scope == nil || ref.Scope.Kind != resolve.Subroutine {
+			continue
+		}
+
+		if ref.Symbol == nil || ref.Symbol.Kind != resolve.Subroutine {
+			continue
+		}
+
+		e := &Edge{
+			Caller: g.nodes[ref.Scope.Name],
+			Callee: g.nodes[ref.Symbol.Name],
+			Pos:    ref.Pos,
+		}
+
+		e.Caller.Calls = append(e.Caller.Calls, e)
+		e.Callee.Callers = append(e.Callee.Callers, e)
+	}
+
+	return g, g.checkCycles()
+}
+
+// Node returns the subroutine by the name, nil if not found
+func (g *Graph) Node(name string) *Node {
+	return g.nodes[name]
+}
+
+// EntryPoints returns the VCL state entry points such as vcl_recv
+func (g *Graph) EntryPoints() []*Node {
+	entries := []*Node{}
+	for _, n := range g.Nodes {
+		if n.IsEntryPoint() {
+			entries = append(entries, n)
+		}
+	}
+
+	return entries
+}
+
+// Reachable returns the subroutines which are called directly or indirectly from the named subroutine.
+// The named subroutine itself is not included.
+func (g *Graph) Reachable(name string) []*Node {
+	start := g.nodes[name]
+	if start == nil {
+		return nil
+	}
+
+	seen := map[*Node]bool{start: true}
+	reachable := []*Node{}
+	queue := []*Node{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, callee := range n.Callees() {
+			if seen[callee] {
+				continue
+			}
+
+			seen[callee] = true
+			reachable = append(reachable, callee)
+			queue = append(queue, callee)
+		}
+	}
+
+	return reachable
+}
+
+const (
+	white = iota // not visited
+	grey         // visiting
+	black        // visited
+)
+
+// checkCycles reports every back edge of the depth first search as a recursion.
+func (g *Graph) checkCycles() []error {
+	errs := []error{}
+	colors := map[*Node]int{}
+	stack := []*Node{}
+
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		colors[n] = grey
+		stack = append(stack, n)
+
+		for _, e := range n.Calls {
+			switch colors[e.Callee] {
+			case white:
+				visit(e.Callee)
+			case grey:
+				errs = append(errs, &Error{
+					Pos: e.Pos,
+					Msg: fmt.Sprintf("recursive call is not allowed: %s", cyclePath(stack, e.Callee)),
+				})
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		colors[n] = black
+	}
+
+	for _, n := range g.Nodes {
+		if colors[n] == white {
+			visit(n)
+		}
+	}
+
+	return errs
+}
+
+// cyclePath formats the cycle from the callee in the stack such as "a -> b -> a"
+func cyclePath(stack []*Node, callee *Node) string {
+	names := []string{}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == callee {
+			for _, n := range stack[i:] {
+				names = append(names, n.Name())
+			}
+			break
+		}
+	}
+
+	names = append(names, callee.Name())
+	return strings.Join(names, " -> ")
+}
+
+// WriteDOT writes the call graph in the DOT language of Graphviz.
+// Entry points are drawn as boxes.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph vcl {")
+	for _, n := range g.Nodes {
+		if n.IsEntryPoint() {
+			fmt.Fprintf(bw, "\t%q [shape=box];\n", n.Name())
+		} else {
+			fmt.Fprintf(bw, "\t%q;\n", n.Name())
+		}
+	}
+
+	for _, n := range g.Nodes {
+		for _, callee := range n.Callees() {
+			fmt.Fprintf(bw, "\t%q -> %q;\n", n.Name(), callee.Name())
+		}
+	}
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
@@ -0,0 +1,172 @@
+package callgraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+)
+
+func newGraph(t *testing.T, input string) (*Graph, []error) {
+	t.Helper()
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+
+	table, errs := resolve.Resolve(program)
+	if len(errs) > 0 {
+		t.Fatalf("resolve has errors, errs:%v", errs)
+	}
+
+	return New(table)
+}
+
+const testProgram = `sub normalize {
+	call strip_cookies;
+}
+
+sub strip_cookies {
+	unset req.http.Cookie;
+}
+
+sub set_cache {
+	set beresp.ttl = 10;
+}
+
+sub vcl_recv {
+	call normalize;
+	call normalize;
+	return (lookup);
+}
+
+sub vcl_fetch {
+	call set_cache;
+}
+`
+
+func TestNew(t *testing.T) {
+	g, errs := newGraph(t, testProgram)
+	if len(errs) > 0 {
+		t.Fatalf("callgraph has errors, errs:%v", errs)
+	}
+
+	if len(g.Nodes) != 5 {
+		t.Fatalf("nodes length wrong, got:%d, want:%d", len(g.Nodes), 5)
+	}
+
+	entries := g.EntryPoints()
+	if len(entries) != 2 || entries[0].Name() != "vcl_recv" || entries[1].Name() != "vcl_fetch" {
+		t.Fatalf("entry points wrong, got:%v", entries)
+	}
+
+	recv := g.Node("vcl_recv")
+	if len(recv.Calls) != 2 {
+		t.Fatalf("calls of vcl_recv wrong, got:%d, want:%d", len(recv.Calls), 2)
+	}
+
+	if len(recv.Callees()) != 1 {
+		t.Fatalf("callees of vcl_recv wrong, got:%d, want:%d", len(recv.Callees()), 1)
+	}
+
+	if len(g.Node("normalize").Callers) != 2 {
+		t.Fatalf("callers of normalize wrong, got:%d, want:%d", len(g.Node("normalize").Callers), 2)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	g, errs := newGraph(t, testProgram)
+	if len(errs) > 0 {
+		t.Fatalf("callgraph has errors, errs:%v", errs)
+	}
+
+	testCases := map[string]struct {
+		entry    string
+		expected []string
+	}{
+		"with vcl_recv":  {"vcl_recv", []string{"normalize", "strip_cookies"}},
+		"with vcl_fetch": {"vcl_fetch", []string{"set_cache"}},
+		"with leaf":      {"strip_cookies", []string{}},
+		"with undefined": {"vcl_deliver", []string{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			nodes := g.Reachable(tc.entry)
+			names := []string{}
+			for _, n := range nodes {
+				names = append(names, n.Name())
+			}
+
+			if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("reachable subroutines wrong, got:%v, want:%v", names, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNew_Cycles(t *testing.T) {
+	testCases := map[string]struct {
+		input       string
+		expectedErr string
+	}{
+		"with self call": {`sub a {
+	call a;
+}`, "2:7: recursive call is not allowed: a -> a"},
+		"with indirect call": {`sub a {
+	call b;
+}
+
+sub b {
+	call c;
+}
+
+sub c {
+	call a;
+}`, "10:7: recursive call is not allowed: a -> b -> c -> a"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, errs := newGraph(t, tc.input)
+			if len(errs) != 1 {
+				t.Fatalf("callgraph errors length wrong, got:%v, want:%s", errs, tc.expectedErr)
+			}
+
+			if errs[0].Error() != tc.expectedErr {
+				t.Fatalf("callgraph error wrong, got:%s, want:%s", errs[0], tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g, errs := newGraph(t, testProgram)
+	if len(errs) > 0 {
+		t.Fatalf("callgraph has errors, errs:%v", errs)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatalf("writeDOT failed, err:%v", err)
+	}
+
+	expected := `digraph vcl {
+	"normalize";
+	"strip_cookies";
+	"set_cache";
+	"vcl_recv" [shape=box];
+	"vcl_fetch" [shape=box];
+	"normalize" -> "strip_cookies";
+	"vcl_recv" -> "normalize";
+	"vcl_fetch" -> "set_cache";
+}
+`
+
+	if buf.String() != expected {
+		t.Fatalf("writeDOT got wrong result, got:%s, want:%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,18 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Error is an error found by the checks
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
@@ -0,0 +1,84 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/callgraph"
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Returns reports the `return` actions which are not allowed in the subroutines by the dialect, such as return(deliver) in vcl_recv.
+// `return` in custom subroutines ends the built-in subroutine which calls them, so they are checked against every caller state.
+func Returns(table *resolve.SymbolTable, d *dialect.Dialect) []error {
+	graph, _ := callgraph.New(table)
+
+	errs := []error{}
+	for _, entry := range graph.EntryPoints() {
+		sub := d.Subroutine(entry.Name())
+		if sub == nil {
+			continue
+		}
+
+		for _, p := range paths(entry) {
+			errs = append(errs, checkReturns(p, sub)...)
+		}
+	}
+
+	return errs
+}
+
+func checkReturns(p *callPath, sub *dialect.Subroutine) []error {
+	errs := []error{}
+
+	for _, decl := range p.node.Sub.Decls {
+		if decl.Blocks == nil {
+			continue
+		}
+
+		ast.Inspect(decl.Blocks, func(node ast.Node) bool {
+			stmt, ok := node.(*ast.ReturnStatement)
+			if !ok {
+				return true
+			}
+
+			action, pos, ok := returnAction(stmt)
+			if !ok {
+				errs = append(errs, &Error{
+					Pos: stmt.Token.Pos,
+					Msg: fmt.Sprintf("invalid return action in %s%s", sub.Name, p.suffix()),
+				})
+				return false
+			}
+
+			if !sub.AllowsReturn(action) {
+				errs = append(errs, &Error{
+					Pos: pos,
+					Msg: fmt.Sprintf("return(%s) is not allowed in %s%s, want one of %s", action, sub.Name, p.suffix(), strings.Join(sub.Returns, ", ")),
+				})
+			}
+
+			return false
+		})
+	}
+
+	return errs
+}
+
+// returnAction returns the action of the `return` such as "pass" in return(pass) or "synth" in return(synth(404))
+func returnAction(stmt *ast.ReturnStatement) (string, token.Position, bool) {
+	expr := stmt.ReturnValue
+	if call, ok := expr.(*ast.CallExpression); ok {
+		expr = call.Function
+	}
+
+	ident, ok := expr.(*ast.Identifier)
+	if !ok {
+		return "", token.Position{}, false
+	}
+
+	return strings.ToLower(ident.Value), ident.Token.Pos, true
+}
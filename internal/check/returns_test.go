@@ -0,0 +1,68 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+)
+
+func TestReturns(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		dialect  *dialect.Dialect
+		expected []string
+	}{
+		"with allowed actions": {`sub vcl_recv {
+	if (req.method == "PURGE") {
+		return (pass);
+	}
+	return (lookup);
+}
+
+sub vcl_deliver {
+	return (deliver);
+}`, dialect.Fastly, []string{}},
+		"with disallowed action": {`sub vcl_deliver {
+	return (pass);
+}`, dialect.Fastly, []string{"2:10: return(pass) is not allowed in vcl_deliver, want one of deliver, restart"}},
+		"with custom subroutine": {`sub bypass {
+	return (pass);
+}
+
+sub vcl_recv {
+	call bypass;
+}
+
+sub vcl_deliver {
+	call bypass;
+}`, dialect.Fastly, []string{"2:10: return(pass) is not allowed in vcl_deliver (called via sub bypass), want one of deliver, restart"}},
+		"with varnish action with argument": {`sub vcl_recv {
+	return (synth(404));
+}
+
+sub vcl_backend_response {
+	return (lookup);
+}`, dialect.Varnish, []string{"6:10: return(lookup) is not allowed in vcl_backend_response, want one of deliver, retry, error, abandon, fail"}},
+		"with invalid action": {`sub vcl_recv {
+	return ("pass");
+}`, dialect.Fastly, []string{"2:2: invalid return action in vcl_recv"}},
+		"with unknown subroutine in dialect": {`sub vcl_backend_response {
+	return (anything);
+}`, dialect.Fastly, []string{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			errs := Returns(symbols(t, tc.input), tc.dialect)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("errors length failed, got:%v, want:%v", errs, tc.expected)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.expected[i] {
+					t.Fatalf("error[%d] failed, got:%s, want:%s", i, err, tc.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/callgraph"
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+)
+
+// callPath is a subroutine reached from the built-in subroutine with the custom subroutines on the way
+type callPath struct {
+	node *callgraph.Node
+	via  []string // names of the custom subroutines from the outermost, empty for the built-in subroutine itself
+}
+
+// suffix describes how the subroutine is called such as " (called via sub a -> b)"
+func (p *callPath) suffix() string {
+	if len(p.via) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (called via sub %s)", strings.Join(p.via, " -> "))
+}
+
+// paths returns the built-in subroutine and the subroutines called from it by the shortest call path
+func paths(entry *callgraph.Node) []*callPath {
+	start := &callPath{node: entry, via: []string{}}
+	seen := map[*callgraph.Node]bool{entry: true}
+	ret := []*callPath{start}
+	queue := []*callPath{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		for _, callee := range p.node.Callees() {
+			if seen[callee] {
+				continue
+			}
+
+			seen[callee] = true
+			via := append(append([]string{}, p.via...), callee.Name())
+			next := &callPath{node: callee, via: via}
+			ret = append(ret, next)
+			queue = append(queue, next)
+		}
+	}
+
+	return ret
+}
+
+// Scopes reports the variables used in the subroutines where the dialect does not allow them, such as beresp.ttl in vcl_recv.
+// Custom subroutines are checked against every built-in subroutine which calls them directly or indirectly.
+func Scopes(table *resolve.SymbolTable, d *dialect.Dialect) []error {
+	graph, _ := callgraph.New(table)
+
+	errs := []error{}
+	for _, entry := range graph.EntryPoints() {
+		sub := d.Subroutine(entry.Name())
+		if sub == nil {
+			continue
+		}
+
+		for _, p := range paths(entry) {
+			errs = append(errs, checkScope(p, sub)...)
+		}
+	}
+
+	return errs
+}
+
+func checkScope(p *callPath, sub *dialect.Subroutine) []error {
+	errs := []error{}
+
+	check := func(name *ast.Identifier, need dialect.Access) {
+		access := sub.Access(name.Value)
+		if access&need == need {
+			return
+		}
+
+		var msg string
+		switch {
+		case access == dialect.NoAccess:
+			msg = "is not accessible from"
+		case need == dialect.Write:
+			msg = "is read-only in"
+		default:
+			msg = "is not readable in"
+		}
+
+		errs = append(errs, &Error{
+			Pos: name.Token.Pos,
+			Msg: fmt.Sprintf("%s %s %s%s", name.Value, msg, sub.Name, p.suffix()),
+		})
+	}
+
+	for _, decl := range p.node.Sub.Decls {
+		if decl.Blocks == nil {
+			continue
+		}
+
+		ast.InspectReads(decl.Blocks, func(node ast.Node) bool {
+			switch v := node.(type) {
+			case *ast.SetStatement:
+				check(v.Name, dialect.Write)
+			case *ast.UnsetStatement:
+				check(v.Name, dialect.Write)
+			case *ast.Identifier:
+				check(v, dialect.Read)
+			}
+
+			return true
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,97 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+)
+
+func symbols(t *testing.T, input string) *resolve.SymbolTable {
+	t.Helper()
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser has errors, errs:%v", p.Errors())
+	}
+
+	table, _ := resolve.Resolve(program)
+	return table
+}
+
+func TestScopes(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		dialect  *dialect.Dialect
+		expected []string
+	}{
+		"with accessible variables": {`sub vcl_recv {
+	set req.http.X-Foo = client.ip;
+}
+
+sub vcl_fetch {
+	set beresp.ttl = 10s;
+	set req.http.X-Url = bereq.url;
+}`, dialect.Fastly, []string{}},
+		"with inaccessible variable": {`sub vcl_recv {
+	set beresp.ttl = 10s;
+	if (resp.status == 200) {}
+}`, dialect.Fastly, []string{"2:6: beresp.ttl is not accessible from vcl_recv", "3:6: resp.status is not accessible from vcl_recv"}},
+		"with read-only variable": {`sub vcl_fetch {
+	set bereq.url = "/";
+	unset bereq.http.Cookie;
+}`, dialect.Fastly, []string{"2:6: bereq.url is read-only in vcl_fetch", "3:8: bereq.http.Cookie is read-only in vcl_fetch"}},
+		"with custom subroutine": {`sub set_cache {
+	set beresp.ttl = 10s;
+}
+
+sub vcl_fetch {
+	call set_cache;
+}
+
+sub vcl_recv {
+	call set_cache;
+}`, dialect.Fastly, []string{"2:6: beresp.ttl is not accessible from vcl_recv (called via sub set_cache)"}},
+		"with nested custom subroutine": {`sub inner {
+	set resp.http.X-Foo = "1";
+}
+
+sub outer {
+	call inner;
+}
+
+sub vcl_recv {
+	call outer;
+}`, dialect.Fastly, []string{"2:6: resp.http.X-Foo is not accessible from vcl_recv (called via sub outer -> inner)"}},
+		"with varnish dialect": {`sub vcl_backend_response {
+	set beresp.ttl = 10s;
+	set req.url = "/";
+}
+
+sub vcl_fetch {
+	set beresp.ttl = 10s;
+}`, dialect.Varnish, []string{"3:6: req.url is not accessible from vcl_backend_response"}},
+		"with uncalled custom subroutine": {`sub helper {
+	set beresp.ttl = 10s;
+}`, dialect.Fastly, []string{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			errs := Scopes(symbols(t, tc.input), tc.dialect)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("errors length failed, got:%v, want:%v", errs, tc.expected)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.expected[i] {
+					t.Fatalf("error[%d] failed, got:%s, want:%s", i, err, tc.expected[i])
+				}
+			}
+		})
+	}
+}
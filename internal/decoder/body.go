@@ -0,0 +1,66 @@
+package decoder
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+var blockStatementType = reflect.TypeOf((*ast.BlockStatement)(nil))
+
+// decodeBody sets the raw body of the block such as the statements of the subroutine to the body fields.
+// The field can be *ast.BlockStatement, []ast.Statement or string which is the source text between the braces.
+func (d *decoder) decodeBody(block *schema.Block, tags *fieldTags, val reflect.Value, loc location) []error {
+	errs := []error{}
+	for _, n := range tags.Bodies {
+		field := val.Type().FieldByIndex(n.FieldIndex)
+		fieldLoc := loc.field(field.Name)
+		fieldV := fieldByIndex(val, n.FieldIndex, true)
+
+		var body *ast.BlockStatement
+		if block.Expr != nil {
+			body = block.Expr.Blocks
+		}
+
+		if body == nil {
+			errs = append(errs, fieldLoc.errorf(block.Pos, "%s block has no body", block.Type))
+			continue
+		}
+
+		switch {
+		case field.Type == blockStatementType:
+			fieldV.Set(reflect.ValueOf(body))
+		case field.Type == statementsType:
+			fieldV.Set(reflect.ValueOf(body.Statements))
+		case field.Type.Kind() == reflect.String:
+			text, err := d.sourceText(body)
+			if err != nil {
+				errs = append(errs, fieldLoc.wrap(block.Pos, err))
+				continue
+			}
+
+			fieldV.SetString(text)
+		default:
+			errs = append(errs, fieldLoc.errorf(token.Position{}, "vcl 'body' tag kind cannot be applied to %s: *ast.BlockStatement, []ast.Statement or string required", field.Type.String()))
+		}
+	}
+
+	return errs
+}
+
+// sourceText returns the source text between the braces of the body
+func (d *decoder) sourceText(body *ast.BlockStatement) (string, error) {
+	if d.source == nil {
+		return "", errors.New("source text is not available")
+	}
+
+	start, end := body.Token.Pos.Offset+1, body.Rbrace.Offset
+	if !body.Rbrace.IsValid() || start > end || end > len(d.source) {
+		return "", errors.New("body has no closing brace")
+	}
+
+	return string(d.source[start:end]), nil
+}
@@ -0,0 +1,229 @@
+package decoder
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	ipType       = reflect.TypeOf(net.IP{})
+)
+
+// rtimeUnits are the units of the relative time such as 10s
+var rtimeUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// convert returns the value of the attribute as the type.
+// The values are string, int64 or bool from the literals, and they are converted by the rules
+//
+//	integers          int64 and the numeric strings with the overflow check
+//	floats            int64, the numeric strings and the percentages such as "50%" which is 50
+//	time.Duration     the relative times such as "10s"
+//	net.IP            the strings such as "192.0.2.1"
+//	bool              bool and the strings such as "true"
+//	string            all values
+//
+// Pointers are allocated for the element type.
+func convert(v interface{}, ty reflect.Type) (reflect.Value, error) {
+	if ty.Kind() == reflect.Ptr {
+		elem, err := convert(v, ty.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(ty.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	rv := reflect.New(ty).Elem()
+	switch {
+	case ty == durationType:
+		s, ok := v.(string)
+		if !ok {
+			return cannotConvert(v, ty, "relative time such as 10s required")
+		}
+
+		d, err := parseRTime(s)
+		if err != nil {
+			return cannotConvert(v, ty, err.Error())
+		}
+
+		rv.SetInt(int64(d))
+		return rv, nil
+	case ty == ipType:
+		s, ok := v.(string)
+		if !ok {
+			return cannotConvert(v, ty, "string required")
+		}
+
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return cannotConvert(v, ty, "invalid IP address")
+		}
+
+		rv.Set(reflect.ValueOf(ip))
+		return rv, nil
+	}
+
+	switch ty.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := v.(type) {
+		case int64:
+			n = v
+		case string:
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return cannotConvert(v, ty, "integer required")
+			}
+			n = i
+		default:
+			return cannotConvert(v, ty, "integer required")
+		}
+
+		if rv.OverflowInt(n) {
+			return cannotConvert(v, ty, "overflows")
+		}
+
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch v := v.(type) {
+		case int64:
+			if v < 0 {
+				return cannotConvert(v, ty, "negative value")
+			}
+			n = uint64(v)
+		case string:
+			u, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return cannotConvert(v, ty, "unsigned integer required")
+			}
+			n = u
+		default:
+			return cannotConvert(v, ty, "unsigned integer required")
+		}
+
+		if rv.OverflowUint(n) {
+			return cannotConvert(v, ty, "overflows")
+		}
+
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := v.(type) {
+		case int64:
+			f = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+			if err != nil {
+				return cannotConvert(v, ty, "number or percentage required")
+			}
+			f = parsed
+		default:
+			return cannotConvert(v, ty, "number or percentage required")
+		}
+
+		if rv.OverflowFloat(f) {
+			return cannotConvert(v, ty, "overflows")
+		}
+
+		rv.SetFloat(f)
+	case reflect.Bool:
+		switch v := v.(type) {
+		case bool:
+			rv.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return cannotConvert(v, ty, "boolean required")
+			}
+			rv.SetBool(b)
+		default:
+			return cannotConvert(v, ty, "boolean required")
+		}
+	case reflect.String:
+		switch v := v.(type) {
+		case string:
+			rv.SetString(v)
+		case int64:
+			rv.SetString(strconv.FormatInt(v, 10))
+		case bool:
+			rv.SetString(strconv.FormatBool(v))
+		default:
+			return cannotConvert(v, ty, "string required")
+		}
+	default:
+		if !reflect.TypeOf(v).AssignableTo(ty) {
+			return cannotConvert(v, ty, "unsupported type")
+		}
+
+		rv.Set(reflect.ValueOf(v))
+	}
+
+	return rv, nil
+}
+
+func cannotConvert(v interface{}, ty reflect.Type, reason string) (reflect.Value, error) {
+	value := fmt.Sprint(v)
+	if s, ok := v.(string); ok {
+		value = strconv.Quote(s)
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot decode %s into %s: %s", value, ty.String(), reason)
+}
+
+// parseRTime parses the relative time such as 10s or 1.5h
+func parseRTime(s string) (time.Duration, error) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return 'a' <= r && r <= 'z'
+	})
+
+	if i <= 0 {
+		return 0, fmt.Errorf("relative time such as 10s required")
+	}
+
+	unit, ok := rtimeUnits[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", s[i:])
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("relative time such as 10s required")
+	}
+
+	return time.Duration(n * float64(unit)), nil
+}
+
+// naturalValue returns the value of the attribute for the interface fields.
+// Relative times are time.Duration and percentages are float64 such as 50 for 50%.
+func naturalValue(attr *schema.Attribute) interface{} {
+	switch lit := attr.Expr.(type) {
+	case *ast.RTimeLiteral:
+		if d, err := parseRTime(lit.Value); err == nil {
+			return d
+		}
+	case *ast.PercentageLiteral:
+		if f, err := strconv.ParseFloat(strings.TrimSuffix(lit.Value, "%"), 64); err == nil {
+			return f
+		}
+	}
+
+	return attr.Value
+}
@@ -9,76 +9,140 @@ import (
 
 	"github.com/KeisukeYamashita/go-vcl/internal/ast"
 	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
 	"github.com/KeisukeYamashita/go-vcl/internal/traversal"
 )
 
-var attrType = reflect.TypeOf((*schema.Attribute)(nil))
+var (
+	attrType  = reflect.TypeOf((*schema.Attribute)(nil))
+	entryType = reflect.TypeOf((*schema.DirectorBackendEntry)(nil))
+)
 
 // Decode is a function for mapping the program of parser output to your custom struct.
-func Decode(program *ast.Program, val interface{}) []error {
+func Decode(program *ast.Program, val interface{}, opts ...Option) []error {
 	rv := reflect.ValueOf(val)
 	if rv.Kind() != reflect.Ptr {
-		return []error{fmt.Errorf("target value must be a pointer, not: %s", rv.Type().String())}
+		return []error{fmt.Errorf("target value must be a pointer, not: %T", val)}
+	}
+
+	if rv.IsNil() {
+		return []error{errors.New("target value must be a non-nil pointer")}
 	}
 
-	return decodeProgramToValue(program, rv.Elem())
+	return decodeProgramToValue(program, rv.Elem(), opts...)
 }
 
-func decodeProgramToValue(program *ast.Program, val reflect.Value) []error {
+func decodeProgramToValue(program *ast.Program, val reflect.Value, opts ...Option) []error {
 	et := val.Type()
 	switch et.Kind() {
 	case reflect.Struct:
-		return decodeProgramToStruct(program, val)
+		return decodeProgramToStruct(program, val, opts...)
 	case reflect.Map:
 		return decodeProgramToMap(program, val)
 	default:
-		panic(fmt.Sprintf("target value must be a pointer to struct, not: %s", et.String()))
+		return []error{fmt.Errorf("target value must be a pointer to struct or map, not: %s", et.String())}
 	}
 }
 
-func decodeProgramToStruct(program *ast.Program, val reflect.Value) []error {
-	content := traversal.Content(program)
-	return decodeContentToStruct(content, val)
+func decodeProgramToStruct(program *ast.Program, val reflect.Value, opts ...Option) []error {
+	d := &decoder{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	content, errs := traversal.Content(program)
+	return append(errs, d.decodeContentToStruct(content, val, location{path: val.Type().Name()})...)
 }
 
-func decodeContentToStruct(content *schema.BodyContent, val reflect.Value) []error {
-	tags := getFieldTags(val.Type())
-	decodeAttr(content, tags, val)
-	decodeFlats(content.Flats, tags, val)
-	decodeComments(content.Comments, tags, val)
-	return decodeBlocks(content.Blocks, tags, val)
+func (d *decoder) decodeContentToStruct(content *schema.BodyContent, val reflect.Value, loc location) []error {
+	tags, err := getFieldTags(val.Type())
+	if err != nil {
+		return []error{loc.wrap(token.Position{}, err)}
+	}
+
+	errs := []error{}
+	if d.disallowUnknownFields && len(tags.Remain) == 0 {
+		errs = unknownFields(content, tags, loc)
+	}
+
+	errs = append(errs, decodeAttr(content, tags, val, loc)...)
+	errs = append(errs, d.decodeFlats(content.Flats, tags, val, loc)...)
+	errs = append(errs, decodeComments(content.Comments, tags, val, loc)...)
+	errs = append(errs, d.decodeEntries(content.Entries, tags, val, loc)...)
+	errs = append(errs, d.decodeBlocks(content.Blocks, tags, val, loc)...)
+	errs = append(errs, decodeRemain(content, tags, val, loc)...)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return validate(val, loc)
 }
 
-func decodeAttr(content *schema.BodyContent, tags *fieldTags, val reflect.Value) {
-	for name, fieldIdx := range tags.Attributes {
+func decodeAttr(content *schema.BodyContent, tags *fieldTags, val reflect.Value, loc location) []error {
+	errs := []error{}
+
+	for name, tag := range tags.Attributes {
 		attr := content.Attributes[name]
-		field := val.Type().Field(fieldIdx)
-		fieldTy := field.Type
-		fieldV := val.Field(fieldIdx)
+		field := val.Type().FieldByIndex(tag.FieldIndex)
 
 		if attr == nil {
-			fieldV.Set(reflect.Zero(field.Type))
+			switch {
+			case tag.Default != nil:
+				attr = &schema.Attribute{Name: name, Value: *tag.Default}
+			case tag.Required:
+				errs = append(errs, loc.field(field.Name).errorf(token.Position{}, "missing required attribute %s", name))
+				continue
+			default:
+				if fieldV := fieldByIndex(val, tag.FieldIndex, false); fieldV.IsValid() {
+					fieldV.Set(reflect.Zero(field.Type))
+				}
+				continue
+			}
+		}
+
+		if field.Type == attrType {
+			fieldByIndex(val, tag.FieldIndex, true).Set(reflect.ValueOf(attr))
 			continue
 		}
 
+		v, ok, err := unmarshal(field.Type, attr, attr.Value)
 		switch {
-		case attrType.AssignableTo(field.Type):
-			fieldV.Set(reflect.ValueOf(attr))
-		case fieldTy.AssignableTo(reflect.ValueOf(attr.Value).Type()):
-			fieldV.Set(reflect.ValueOf(attr.Value))
+		case ok:
+		case attr.Value == nil:
+			errs = append(errs, loc.field(field.Name).errorf(attr.Pos, "attribute %s has no value", name))
+			continue
+		case field.Type.Kind() == reflect.Interface:
+			v, err = convert(naturalValue(attr), field.Type)
+		default:
+			v, err = convert(attr.Value, field.Type)
+		}
+
+		if err != nil {
+			errs = append(errs, loc.field(field.Name).wrap(attr.Pos, err))
+			continue
 		}
+
+		fieldByIndex(val, tag.FieldIndex, true).Set(v)
 	}
+
+	return errs
 }
 
-func decodeBlocks(blocks schema.Blocks, tags *fieldTags, val reflect.Value) []error {
+func (d *decoder) decodeBlocks(blocks schema.Blocks, tags *fieldTags, val reflect.Value, loc location) []error {
 	errs := []error{}
 	blocksByType := blocks.ByType()
 
 	for typeName, fieldIdx := range tags.Blocks {
 		blocks := blocksByType[typeName]
-		field := val.Type().Field(fieldIdx)
+		field := val.Type().FieldByIndex(fieldIdx)
+		fieldLoc := loc.field(field.Name)
 		ty := field.Type
 
+		if ty.Kind() == reflect.Map {
+			errs = append(errs, d.decodeBlockMap(typeName, blocks, fieldByIndex(val, fieldIdx, true), fieldLoc)...)
+			continue
+		}
+
 		var isSlice bool
 		var isPtr bool
 		if ty.Kind() == reflect.Slice {
@@ -91,16 +155,24 @@ func decodeBlocks(blocks schema.Blocks, tags *fieldTags, val reflect.Value) []er
 			ty = ty.Elem()
 		}
 
+		if ty.Kind() != reflect.Struct && !isUnmarshaler(ty) {
+			errs = append(errs, fieldLoc.errorf(token.Position{}, "vcl 'block' tag kind cannot be applied to %s: struct required", field.Type.String()))
+			continue
+		}
+
 		if len(blocks) > 1 && !isSlice {
-			errs = append(errs, errors.New("more than one block but the field type is not slice"))
+			errs = append(errs, fieldLoc.in(blocks[1]).errorf(blocks[1].Pos, "more than one %s block but the field type is not slice", typeName))
 		}
 
-		if len(blocks) == 0 {
-			if isSlice || isPtr {
-				val.Field(fieldIdx).Set(reflect.Zero(field.Type))
+		if len(blocks) == 0 && !isSlice {
+			if isPtr {
+				if fieldV := fieldByIndex(val, fieldIdx, false); fieldV.IsValid() {
+					fieldV.Set(reflect.Zero(field.Type))
+				}
 			} else {
-				errs = append(errs, errors.New("no block"))
+				errs = append(errs, fieldLoc.errorf(token.Position{}, "no %s block", typeName))
 			}
+			continue
 		}
 
 		switch {
@@ -113,50 +185,139 @@ func decodeBlocks(blocks schema.Blocks, tags *fieldTags, val reflect.Value) []er
 			sli := reflect.MakeSlice(reflect.SliceOf(elemType), len(blocks), len(blocks))
 
 			for i, block := range blocks {
+				elemLoc := fieldLoc.index(i).in(block)
 				if isPtr {
-					v := reflect.New(ty)
-					decodeBlockToStruct(block, v.Elem())
+					v, blockErrs := d.decodeBlock(block, ty, elemLoc)
+					errs = append(errs, blockErrs...)
 					sli.Index(i).Set(v)
 				} else {
-					errs = append(errs, errors.New("block is not a pointer"))
+					errs = append(errs, elemLoc.errorf(block.Pos, "%s block is not a pointer", typeName))
 				}
 			}
 
-			val.Field(fieldIdx).Set(sli)
+			fieldByIndex(val, fieldIdx, true).Set(sli)
 		default:
+			blockLoc := fieldLoc.in(blocks[0])
 			if isPtr {
-				v := reflect.New(ty)
-				decodeBlockToStruct(blocks[0], v.Elem())
-				val.Field(fieldIdx).Set(v)
+				v, blockErrs := d.decodeBlock(blocks[0], ty, blockLoc)
+				errs = append(errs, blockErrs...)
+				fieldByIndex(val, fieldIdx, true).Set(v)
 			} else {
-				errs = append(errs, errors.New("block is not a pointer"))
+				errs = append(errs, blockLoc.errorf(blocks[0].Pos, "%s block is not a pointer", typeName))
+			}
+		}
+	}
+
+	return errs
+}
+
+// decodeBlockMap decodes the blocks into the map keyed by the first label such as map[string]*Backend.
+// Nested maps such as map[string]map[string]*Director are keyed by the following labels.
+func (d *decoder) decodeBlockMap(typeName string, blocks schema.Blocks, fieldV reflect.Value, loc location) []error {
+	depth := 0
+	ty := fieldV.Type()
+	for ty.Kind() == reflect.Map {
+		if ty.Key().Kind() != reflect.String {
+			return []error{loc.errorf(token.Position{}, "vcl 'block' tag kind cannot be applied to %s: string key required", fieldV.Type().String())}
+		}
+
+		depth++
+		ty = ty.Elem()
+	}
+
+	if ty.Kind() != reflect.Ptr || (ty.Elem().Kind() != reflect.Struct && !isUnmarshaler(ty.Elem())) {
+		return []error{loc.errorf(token.Position{}, "vcl 'block' tag kind cannot be applied to %s: map of pointer to struct required", fieldV.Type().String())}
+	}
+
+	errs := []error{}
+	m := reflect.MakeMap(fieldV.Type())
+	for _, block := range blocks {
+		blockLoc := loc.in(block)
+		if len(block.Labels) < depth {
+			errs = append(errs, blockLoc.errorf(block.Pos, "%s block has %d labels but %d are required for %s", typeName, len(block.Labels), depth, fieldV.Type().String()))
+			continue
+		}
+
+		inner := m
+		for _, label := range block.Labels[:depth-1] {
+			blockLoc = blockLoc.key(label)
+			key := reflect.ValueOf(label).Convert(inner.Type().Key())
+			next := inner.MapIndex(key)
+			if !next.IsValid() {
+				next = reflect.MakeMap(inner.Type().Elem())
+				inner.SetMapIndex(key, next)
 			}
+			inner = next
 		}
+
+		label := block.Labels[depth-1]
+		blockLoc = blockLoc.key(label)
+		key := reflect.ValueOf(label).Convert(inner.Type().Key())
+		if inner.MapIndex(key).IsValid() {
+			errs = append(errs, blockLoc.errorf(block.Pos, "duplicate %s block %s", typeName, strings.Join(block.Labels[:depth], " ")))
+			continue
+		}
+
+		v, blockErrs := d.decodeBlock(block, ty.Elem(), blockLoc)
+		errs = append(errs, blockErrs...)
+		inner.SetMapIndex(key, v)
 	}
 
+	fieldV.Set(m)
 	return errs
 }
 
+// decodeBlock decodes the block into the new value of the type by Unmarshaler or as a struct, and returns the pointer to it
+func (d *decoder) decodeBlock(block *schema.Block, ty reflect.Type, loc location) (reflect.Value, []error) {
+	v := reflect.New(ty)
+	if u, ok := v.Interface().(Unmarshaler); ok {
+		if err := u.UnmarshalVCL(block); err != nil {
+			return v, []error{loc.wrap(block.Pos, err)}
+		}
+
+		return v, nil
+	}
+
+	return v, d.decodeBlockToStruct(block, v.Elem(), loc)
+}
+
 // decodeBlockToStruct decodes a block into a struct passed by val
-func decodeBlockToStruct(block *schema.Block, val reflect.Value) []error {
-	tags := getFieldTags(val.Type())
+func (d *decoder) decodeBlockToStruct(block *schema.Block, val reflect.Value, loc location) []error {
+	tags, err := getFieldTags(val.Type())
+	if err != nil {
+		return []error{loc.wrap(token.Position{}, err)}
+	}
+
+	errs := []error{}
+	if d.disallowUnknownFields && len(block.Labels) != len(tags.Labels) {
+		errs = append(errs, loc.errorf(block.Pos, "%s block has %d labels but %d are expected", block.Type, len(block.Labels), len(tags.Labels)))
+	}
 
 	for i, n := range tags.Labels {
 		if i+1 > len(block.Labels) {
 			continue
 		}
 		label := block.Labels[i]
-		fieldV := val.Field(n.FieldIndex)
-		fieldV.Set(reflect.ValueOf(label))
+		fieldV := fieldByIndex(val, n.FieldIndex, true)
+		if fieldV.Kind() != reflect.String {
+			errs = append(errs, loc.field(val.Type().FieldByIndex(n.FieldIndex).Name).errorf(block.Pos, "label %s cannot be decoded into %s", n.Name, fieldV.Type().String()))
+			continue
+		}
+		fieldV.SetString(label)
 	}
 
+	errs = append(errs, d.decodeBody(block, tags, val, loc)...)
+
 	content := traversal.BodyContent(block.Body)
-	return decodeContentToStruct(content, val)
+	return append(errs, d.decodeContentToStruct(content, val, loc)...)
 }
 
-func decodeFlats(flats schema.Flats, tags *fieldTags, val reflect.Value) {
+func (d *decoder) decodeFlats(flats schema.Flats, tags *fieldTags, val reflect.Value, loc location) []error {
+	errs := []error{}
+
 	for _, n := range tags.Flats {
-		field := val.Type().Field(n.FieldIndex)
+		field := val.Type().FieldByIndex(n.FieldIndex)
+		fieldLoc := loc.field(field.Name)
 		ty := field.Type
 
 		var isSlice bool
@@ -181,23 +342,39 @@ func decodeFlats(flats schema.Flats, tags *fieldTags, val reflect.Value) {
 			sli := reflect.MakeSlice(reflect.SliceOf(elemType), len(flats), len(flats))
 
 			for i, flat := range flats {
-				if isPtr {
-					v := reflect.New(ty)
-					decodeBlockToStruct(flat.(*schema.Block), v.Elem())
+				elemLoc := fieldLoc.index(i)
+				if block, ok := flat.(*schema.Block); ok && isPtr && (ty.Kind() == reflect.Struct || isUnmarshaler(ty)) {
+					v, blockErrs := d.decodeBlock(block, ty, elemLoc.in(block))
+					errs = append(errs, blockErrs...)
 					sli.Index(i).Set(v)
-				} else {
+					continue
+				}
+
+				v, ok, err := unmarshal(elemType, flat, flat)
+				switch {
+				case err != nil:
+					errs = append(errs, elemLoc.wrap(token.Position{}, err))
+				case ok:
+					sli.Index(i).Set(v)
+				case reflect.TypeOf(flat).AssignableTo(elemType):
 					sli.Index(i).Set(reflect.ValueOf(flat))
+				default:
+					errs = append(errs, elemLoc.errorf(token.Position{}, "flat %v cannot be decoded into %s", flat, elemType.String()))
 				}
 			}
 
-			val.Field(n.FieldIndex).Set(sli)
+			fieldByIndex(val, n.FieldIndex, true).Set(sli)
 		}
 	}
+
+	return errs
 }
 
-func decodeComments(comments schema.Comments, tags *fieldTags, val reflect.Value) {
+func decodeComments(comments schema.Comments, tags *fieldTags, val reflect.Value, loc location) []error {
+	errs := []error{}
+
 	for _, n := range tags.Comments {
-		field := val.Type().Field(n.FieldIndex)
+		field := val.Type().FieldByIndex(n.FieldIndex)
 		fieldTy := field.Type
 
 		var isSlice bool
@@ -208,22 +385,71 @@ func decodeComments(comments schema.Comments, tags *fieldTags, val reflect.Value
 
 		switch {
 		case isSlice:
+			if fieldTy.Kind() != reflect.String {
+				errs = append(errs, loc.field(field.Name).errorf(token.Position{}, "comments cannot be decoded into %s", field.Type.String()))
+				continue
+			}
+
 			sli := reflect.MakeSlice(reflect.SliceOf(fieldTy), len(comments), len(comments))
 
 			for i, comment := range comments {
-				sli.Index(i).Set(reflect.ValueOf(comment))
+				sli.Index(i).SetString(comment)
 			}
 
-			val.Field(n.FieldIndex).Set(sli)
+			fieldByIndex(val, n.FieldIndex, true).Set(sli)
 		}
 	}
+
+	return errs
+}
+
+func (d *decoder) decodeEntries(entries schema.Entries, tags *fieldTags, val reflect.Value, loc location) []error {
+	errs := []error{}
+
+	for _, n := range tags.Entries {
+		field := val.Type().FieldByIndex(n.FieldIndex)
+		fieldLoc := loc.field(field.Name)
+		ty := field.Type
+
+		if ty.Kind() != reflect.Slice {
+			errs = append(errs, fieldLoc.errorf(token.Position{}, "entries field must be a slice, not: %s", ty.String()))
+			continue
+		}
+
+		elemType := ty.Elem()
+		sli := reflect.MakeSlice(ty, len(entries), len(entries))
+
+		for i, entry := range entries {
+			switch {
+			case entryType.AssignableTo(elemType):
+				sli.Index(i).Set(reflect.ValueOf(entry))
+			case elemType.Kind() == reflect.Ptr && elemType.Elem().Kind() == reflect.Struct:
+				v := reflect.New(elemType.Elem())
+				content := &schema.BodyContent{
+					Attributes: entry.Attributes,
+					Statements: entry.Statements,
+				}
+				errs = append(errs, d.decodeContentToStruct(content, v.Elem(), fieldLoc.index(i))...)
+				sli.Index(i).Set(v)
+			default:
+				errs = append(errs, fieldLoc.index(i).errorf(token.Position{}, "entry cannot be decoded into %s: pointer to struct required", elemType.String()))
+			}
+		}
+
+		fieldByIndex(val, n.FieldIndex, true).Set(sli)
+	}
+
+	return errs
 }
 
 func decodeProgramToMap(program *ast.Program, val reflect.Value) []error {
-	var errs []error
-	content := traversal.Content(program)
+	if ty := val.Type(); ty.Key().Kind() != reflect.String || ty.Elem().Kind() != reflect.Interface {
+		return []error{fmt.Errorf("target map must be map[string]interface{}, not: %s", ty.String())}
+	}
+
+	content, errs := traversal.Content(program)
 	if content.Attributes == nil {
-		return nil
+		return errs
 	}
 
 	var mv reflect.Value
@@ -250,7 +476,12 @@ func decodeProgramToMap(program *ast.Program, val reflect.Value) []error {
 					v = reflect.New(val.Type()).Elem()
 					decodeBlockToMap(block, v)
 
-					for _, label := range block.Labels[1:] {
+					var labels []string
+					if len(block.Labels) > 1 {
+						labels = block.Labels[1:]
+					}
+
+					for _, label := range labels {
 						tmpMap := reflect.MakeMap(val.Type())
 						tmpMap.SetMapIndex(reflect.ValueOf(label), v)
 						v = tmpMap
@@ -268,7 +499,10 @@ func decodeProgramToMap(program *ast.Program, val reflect.Value) []error {
 		}
 	}
 
-	val.Set(mv)
+	if mv.IsValid() {
+		val.Set(mv)
+	}
+
 	return errs
 }
 
@@ -320,20 +554,23 @@ func decodeBlockToMap(block *schema.Block, val reflect.Value) {
 // imipliedBodySchema will retrieves the root body schema from the given val.
 // For Varnish & Fastly usecases, there will be only blocks in the root. But as a configuration language,
 // the root schema can contain attribute as HCL. Therefore, I left the attributes slice for that.
-func impliedBodySchema(val interface{}) *schema.File {
+func impliedBodySchema(val interface{}) (*schema.File, error) {
 	ty := reflect.TypeOf(val)
-	if ty.Kind() == reflect.Ptr {
+	if ty != nil && ty.Kind() == reflect.Ptr {
 		ty = ty.Elem()
 	}
 
-	if ty.Kind() != reflect.Struct {
-		panic(fmt.Sprintf("target value must be a struct, not: %T", val))
+	if ty == nil || ty.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("target value must be a struct, not: %T", val)
 	}
 
 	var attrSchemas []schema.AttributeSchema
 	var blockSchemas []schema.BlockHeaderSchema
 
-	tags := getFieldTags(ty)
+	tags, err := getFieldTags(ty)
+	if err != nil {
+		return nil, err
+	}
 	attrNames := make([]string, 0, len(tags.Attributes))
 	for n := range tags.Attributes {
 		attrNames = append(attrNames, n)
@@ -341,18 +578,9 @@ func impliedBodySchema(val interface{}) *schema.File {
 
 	sort.Strings(attrNames)
 	for _, n := range attrNames {
-		attr := tags.Attributes[n]
-		field := ty.Field(attr)
-		var required bool
-
-		switch {
-		case field.Type.Kind() != reflect.Ptr:
-			required = true
-		}
-
 		attrSchemas = append(attrSchemas, schema.AttributeSchema{
 			Name:     n,
-			Required: required,
+			Required: tags.Attributes[n].Required,
 		})
 	}
 
@@ -363,18 +591,27 @@ func impliedBodySchema(val interface{}) *schema.File {
 
 	sort.Strings(blockNames)
 	for _, n := range blockNames {
-		idx := tags.Blocks[n]
-		field := ty.Field(idx)
+		field := ty.FieldByIndex(tags.Blocks[n])
 		fty := field.Type
 		if fty.Kind() == reflect.Ptr {
 			fty = fty.Elem()
 		}
 
+		if isUnmarshaler(fty) {
+			blockSchemas = append(blockSchemas, schema.BlockHeaderSchema{
+				Type: n,
+			})
+			continue
+		}
+
 		if fty.Kind() != reflect.Struct {
-			panic(fmt.Sprintf("hcl 'block' tag kind cannot be applied to %s field %s: struct required", field.Type.String(), field.Name))
+			return nil, fmt.Errorf("vcl 'block' tag kind cannot be applied to %s field %s: struct required", field.Type.String(), field.Name)
 		}
 
-		ftags := getFieldTags(fty)
+		ftags, err := getFieldTags(fty)
+		if err != nil {
+			return nil, err
+		}
 		var labelNames []string
 		if len(ftags.Labels) > 0 {
 			labelNames = make([]string, len(ftags.Labels))
@@ -396,87 +633,256 @@ func impliedBodySchema(val interface{}) *schema.File {
 		},
 	}
 
-	return file
+	return file, nil
 }
 
 // fieldTags is a struct that represents info about the field of the passed val.
 type fieldTags struct {
-	Attributes map[string]int
-	Blocks     map[string]int
+	Attributes map[string]attrField
+	Blocks     map[string][]int
 	Labels     []labelField
 	Flats      []flatField
 	Comments   []commentField
+	Entries    []entriesField
+	Remain     []remainField
+	Bodies     []bodyField
+}
+
+// attrField is an attribute field with the options such as `vcl:".port,attr,required"`
+type attrField struct {
+	FieldIndex []int
+	Required   bool
+	Default    *string // nil if there is no default value
 }
 
 // labelField is a struct that represents info about the struct tags of "vcl".
 type labelField struct {
-	FieldIndex int
+	FieldIndex []int
 	Name       string
 }
 type flatField struct {
-	FieldIndex int
+	FieldIndex []int
 	Name       string
 }
 
 type commentField struct {
-	FieldIndex int
+	FieldIndex []int
+	Name       string
+}
+
+type entriesField struct {
+	FieldIndex []int
+	Name       string
+}
+
+type remainField struct {
+	FieldIndex []int
+	Name       string
+}
+
+type bodyField struct {
+	FieldIndex []int
 	Name       string
 }
 
 // getFieldTags retrieves the "vcl" tags of the given struct type.
-func getFieldTags(ty reflect.Type) *fieldTags {
+func getFieldTags(ty reflect.Type) (*fieldTags, error) {
+	if ty.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("target value must be a struct, not: %s", ty.String())
+	}
+
 	ret := &fieldTags{
-		Attributes: map[string]int{},
-		Blocks:     map[string]int{},
+		Attributes: map[string]attrField{},
+		Blocks:     map[string][]int{},
 		Labels:     []labelField{},
 		Flats:      []flatField{},
 		Comments:   []commentField{},
+		Entries:    []entriesField{},
+		Remain:     []remainField{},
+		Bodies:     []bodyField{},
+	}
+
+	if err := ret.collect(ty, nil, map[string]int{}, map[reflect.Type]bool{}); err != nil {
+		return nil, err
 	}
 
+	return ret, nil
+}
+
+// collect adds the tagged fields of the struct type whose index path starts with index.
+// The fields of the embedded structs without the tag are flattened like encoding/json, and the attributes and the blocks
+// of the shallower fields win if the names conflict. depths has the depth of them by the kind and the name.
+func (ret *fieldTags) collect(ty reflect.Type, index []int, depths map[string]int, visiting map[reflect.Type]bool) error {
+	visiting[ty] = true
+	defer delete(visiting, ty)
+
+	var embedded []int
 	ct := ty.NumField()
 	for i := 0; i < ct; i++ {
 		field := ty.Field(i)
+		idx := append(append([]int{}, index...), i)
 		tag := field.Tag.Get("vcl")
 		if tag == "" {
+			if isEmbeddedStruct(field) && !visiting[indirect(field.Type)] {
+				embedded = append(embedded, i)
+			}
 			continue
 		}
 
-		comma := strings.Index(tag, ",")
-		var name, kind string
-		if comma != -1 {
-			name = tag[:comma]
-			kind = tag[comma+1:]
-		} else {
-			name = tag
-			kind = "attr"
+		name, kind, opts, err := parseTag(tag)
+		if err != nil {
+			return fmt.Errorf("%s on %s %q", err, field.Type.String(), field.Name)
+		}
+
+		if kind != "attr" && (opts.Required || opts.Default != nil) {
+			return fmt.Errorf("vcl field tag options cannot be applied to %q kind on %s %q", kind, field.Type.String(), field.Name)
 		}
 
 		switch kind {
-		case "attr":
-			ret.Attributes[name] = i
-		case "block":
-			ret.Blocks[name] = i
+		case "attr", "block":
+			key := kind + " " + name
+			if depth, ok := depths[key]; ok {
+				if depth == len(idx) {
+					return fmt.Errorf("duplicate vcl %s %q on %s %q", kind, name, field.Type.String(), field.Name)
+				}
+
+				if depth < len(idx) {
+					continue
+				}
+			}
+
+			depths[key] = len(idx)
+			if kind == "attr" {
+				opts.FieldIndex = idx
+				ret.Attributes[name] = opts
+			} else {
+				ret.Blocks[name] = idx
+			}
 		case "label":
 			ret.Labels = append(ret.Labels, labelField{
-				FieldIndex: i,
+				FieldIndex: idx,
 				Name:       name,
 			})
 		case "flat":
 			ret.Flats = append(ret.Flats, flatField{
-				FieldIndex: i,
+				FieldIndex: idx,
 				Name:       name,
 			})
 		case "comment":
 			ret.Comments = append(ret.Comments, commentField{
-				FieldIndex: i,
+				FieldIndex: idx,
+				Name:       name,
+			})
+		case "entries":
+			ret.Entries = append(ret.Entries, entriesField{
+				FieldIndex: idx,
+				Name:       name,
+			})
+		case "remain":
+			ret.Remain = append(ret.Remain, remainField{
+				FieldIndex: idx,
+				Name:       name,
+			})
+		case "body":
+			ret.Bodies = append(ret.Bodies, bodyField{
+				FieldIndex: idx,
 				Name:       name,
 			})
+		}
+	}
+
+	for _, i := range embedded {
+		idx := append(append([]int{}, index...), i)
+		if err := ret.collect(indirect(ty.Field(i).Type), idx, depths, visiting); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isEmbeddedStruct reports whether the field is an embedded struct or an embedded pointer to struct which can be allocated
+func isEmbeddedStruct(field reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+
+	if field.Type.Kind() == reflect.Ptr {
+		return field.PkgPath == "" && field.Type.Elem().Kind() == reflect.Struct
+	}
+
+	return field.Type.Kind() == reflect.Struct
+}
+
+func indirect(ty reflect.Type) reflect.Type {
+	if ty.Kind() == reflect.Ptr {
+		return ty.Elem()
+	}
+
+	return ty
+}
+
+// fieldByIndex returns the field of the index path such as the field of the embedded struct.
+// The nil embedded pointers on the way are allocated if alloc is true, otherwise the invalid value is returned for them.
+func fieldByIndex(val reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+
+			val = val.Elem()
+		}
+
+		val = val.Field(x)
+	}
+
+	return val
+}
+
+var tagKinds = map[string]bool{
+	"attr":    true,
+	"block":   true,
+	"label":   true,
+	"flat":    true,
+	"comment": true,
+	"entries": true,
+	"remain":  true,
+	"body":    true,
+}
+
+// parseTag parses the "vcl" tag such as `.port,attr,required,default=80` into the name, the kind and the options.
+// The kind can be omitted such as `.port,required` and then it is "attr".
+func parseTag(tag string) (name, kind string, opts attrField, err error) {
+	parts := strings.Split(tag, ",")
+	name, kind = parts[0], "attr"
+	parts = parts[1:]
+
+	hasKind := len(parts) > 0 && tagKinds[parts[0]]
+	if hasKind {
+		kind = parts[0]
+		parts = parts[1:]
+	}
+
+	for i, part := range parts {
+		switch {
+		case part == "required":
+			opts.Required = true
+		case strings.HasPrefix(part, "default="):
+			def := strings.TrimPrefix(part, "default=")
+			opts.Default = &def
+		case i == 0 && !hasKind:
+			return "", "", attrField{}, fmt.Errorf("invalid vcl field tag kind %q", part)
 		default:
-			panic(fmt.Sprintf("invalid vcl field tag kind %q on %s %q", kind, field.Type.String(), field.Name))
+			return "", "", attrField{}, fmt.Errorf("invalid vcl field tag option %q", part)
 		}
 	}
 
-	return ret
+	return name, kind, opts, nil
 }
 
 func removeAttrDot(v interface{}) interface{} {
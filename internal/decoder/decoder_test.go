@@ -1,13 +1,20 @@
 package decoder
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/KeisukeYamashita/go-vcl/internal/ast"
 	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
 	"github.com/KeisukeYamashita/go-vcl/internal/parser"
 	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
 )
 
 func TestDecode(t *testing.T) {
@@ -23,8 +30,11 @@ func TestDecode(t *testing.T) {
 		program     *ast.Program
 		shouldError bool
 	}{
-		"with pointer":     {testStruct, prog, false},
-		"with not-pointer": {*testStruct, prog, true},
+		"with pointer":        {testStruct, prog, false},
+		"with not-pointer":    {*testStruct, prog, true},
+		"with nil pointer":    {(*TestStruct)(nil), prog, true},
+		"with nil":            {nil, prog, true},
+		"with pointer to int": {new(int), prog, true},
 	}
 
 	for n, tc := range testCases {
@@ -46,8 +56,9 @@ func TestDecode(t *testing.T) {
 
 func TestDecodeProgramToStruct_Attribute(t *testing.T) {
 	type Root struct {
-		X   int64  `vcl:"x"`
-		API string `vcl:"api"`
+		X       int64  `vcl:"x"`
+		API     string `vcl:"api"`
+		Timeout string `vcl:".connect_timeout"`
 	}
 
 	testCases := []struct {
@@ -57,6 +68,7 @@ func TestDecodeProgramToStruct_Attribute(t *testing.T) {
 	}{
 		{`x = 1`, &Root{}, &Root{X: 1}},
 		{`api = "localhost"`, &Root{}, &Root{API: "localhost"}},
+		{`.connect_timeout = 1s;`, &Root{}, &Root{Timeout: "1s"}},
 	}
 
 	for n, tc := range testCases {
@@ -77,6 +89,225 @@ func TestDecodeProgramToStruct_Attribute(t *testing.T) {
 	}
 }
 
+func TestDecodeProgramToStruct_Conversion(t *testing.T) {
+	type Root struct {
+		Int      int            `vcl:"int"`
+		Int8     int8           `vcl:"int8"`
+		Uint16   uint16         `vcl:"uint16"`
+		Float    float64        `vcl:"float"`
+		Quorum   float32        `vcl:"quorum"`
+		Timeout  time.Duration  `vcl:"timeout"`
+		IP       net.IP         `vcl:"ip"`
+		Bool     bool           `vcl:"bool"`
+		Port     int            `vcl:"port"`
+		Name     string         `vcl:"name"`
+		Weight   *int           `vcl:"weight"`
+		Interval *time.Duration `vcl:"interval"`
+	}
+
+	weight := 3
+	interval := 1500 * time.Millisecond
+
+	testCases := map[string]struct {
+		input    string
+		expected *Root
+	}{
+		"with int":              {`int = 1`, &Root{Int: 1}},
+		"with int8":             {`int8 = 127`, &Root{Int8: 127}},
+		"with uint16":           {`uint16 = 65535`, &Root{Uint16: 65535}},
+		"with integer to float": {`float = 2`, &Root{Float: 2}},
+		"with percentage":       {`quorum = 50%`, &Root{Quorum: 50}},
+		"with rtime":            {`timeout = 10s`, &Root{Timeout: 10 * time.Second}},
+		"with rtime in days":    {`timeout = 2d`, &Root{Timeout: 48 * time.Hour}},
+		"with ip":               {`ip = "192.0.2.1"`, &Root{IP: net.ParseIP("192.0.2.1")}},
+		"with bool":             {`bool = true`, &Root{Bool: true}},
+		"with numeric string":   {`port = "8080"`, &Root{Port: 8080}},
+		"with integer string":   {`name = 1`, &Root{Name: "1"}},
+		"with pointer":          {`weight = 3`, &Root{Weight: &weight}},
+		"with pointer to rtime": {`interval = 1500ms`, &Root{Interval: &interval}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			got := &Root{}
+			if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem()); len(errs) > 0 {
+				t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got:%+v, want:%+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_ConversionErrors(t *testing.T) {
+	type Root struct {
+		Int8    int8          `vcl:"int8"`
+		Uint    uint          `vcl:"uint"`
+		Port    int           `vcl:"port"`
+		Timeout time.Duration `vcl:"timeout"`
+		IP      net.IP        `vcl:"ip"`
+		Bool    bool          `vcl:"bool"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"with overflow":         {`int8 = 128`, "1:1: Root.Int8: cannot decode 128 into int8: overflows"},
+		"with negative":         {`uint = "-1"`, `1:1: Root.Uint: cannot decode "-1" into uint: unsigned integer required`},
+		"with non-numeric":      {`port = "abc"`, `1:1: Root.Port: cannot decode "abc" into int: integer required`},
+		"with string duration":  {`timeout = "soon"`, `1:1: Root.Timeout: cannot decode "soon" into time.Duration: relative time such as 10s required`},
+		"with integer to rtime": {`timeout = 10`, "1:1: Root.Timeout: cannot decode 10 into time.Duration: relative time such as 10s required"},
+		"with invalid ip":       {`ip = "localhost"`, `1:1: Root.IP: cannot decode "localhost" into net.IP: invalid IP address`},
+		"with integer to bool":  {`bool = 1`, "1:1: Root.Bool: cannot decode 1 into bool: boolean required"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			got := &Root{}
+			errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem())
+			if len(errs) != 1 {
+				t.Fatalf("errors length wrong, got:%v, want:%d", errs, 1)
+			}
+
+			if errs[0].Error() != tc.expected {
+				t.Fatalf("got:%v, want:%s", errs[0], tc.expected)
+			}
+
+			if !reflect.DeepEqual(got, &Root{}) {
+				t.Fatalf("field should not be set, got:%+v", got)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_Errors(t *testing.T) {
+	type InvalidTag struct {
+		Name string `vcl:"name,unknown"`
+	}
+
+	type StringBlock struct {
+		ACL string `vcl:"acl,block"`
+	}
+
+	type Backend struct {
+		Name int `vcl:"name,label"`
+	}
+
+	type IntLabel struct {
+		Backend *Backend `vcl:"backend,block"`
+	}
+
+	type ACL struct {
+		Endpoints []int64 `vcl:"endpoints,flat"`
+	}
+
+	type IntFlats struct {
+		ACLs []*ACL `vcl:"acl,block"`
+	}
+
+	type Single struct {
+		Backend *struct{} `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		val      interface{}
+		expected string
+	}{
+		"with invalid tag kind":       {`x = 1`, &InvalidTag{}, `InvalidTag: invalid vcl field tag kind "unknown" on string "Name"`},
+		"with non-struct block field": {`acl local {}`, &StringBlock{}, "StringBlock.ACL: vcl 'block' tag kind cannot be applied to string: struct required"},
+		"with non-string label field": {`backend F_x {}`, &IntLabel{}, "1:1: IntLabel.Backend.Name (backend F_x): label name cannot be decoded into int"},
+		"with mismatched flats":       {`acl local { "localhost"; }`, &IntFlats{}, "1:1: IntFlats.ACLs[0].Endpoints[0] (acl local): flat localhost cannot be decoded into int64"},
+		"with duplicated block":       {"backend F_x {}\nbackend F_y {}", &Single{}, "2:1: Single.Backend (backend F_y): more than one backend block but the field type is not slice"},
+		"with invalid value":          {`x = foo(1)`, &InvalidTag{}, "1:1: value of x must be a literal"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			errs := decodeProgramToStruct(program, reflect.ValueOf(tc.val).Elem())
+			if len(errs) == 0 {
+				t.Fatalf("decodeProgramToStruct should fail")
+			}
+
+			if errs[0].Error() != tc.expected {
+				t.Fatalf("decodeProgramToStruct error wrong, got:%v, want:%s", errs, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	type Probe struct {
+		Name string `vcl:"name,label"`
+	}
+
+	type Backend struct {
+		Name  string `vcl:"name,label"`
+		Probe Probe  `vcl:"probe,block"`
+	}
+
+	type Root struct {
+		Backends []*Backend `vcl:"backend,block"`
+	}
+
+	input := `backend F_a {
+	probe p {}
+}
+
+backend F_b {}`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem())
+	if len(errs) != 2 {
+		t.Fatalf("errors length wrong, got:%v, want:%d", errs, 2)
+	}
+
+	testCases := map[string]struct {
+		err      error
+		expected *DecodeError
+	}{
+		"not a pointer": {
+			err:      errs[0],
+			expected: &DecodeError{Path: "Root.Backends[0].Probe", Block: "probe p", Pos: token.Position{Offset: 15, Line: 2, Column: 2}},
+		},
+		"no block": {
+			err:      errs[1],
+			expected: &DecodeError{Path: "Root.Backends[1].Probe", Block: "backend F_b", Pos: token.Position{Offset: 29, Line: 5, Column: 1}},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got *DecodeError
+			if !errors.As(tc.err, &got) {
+				t.Fatalf("error is not DecodeError, got:%T", tc.err)
+			}
+
+			if got.Path != tc.expected.Path || got.Block != tc.expected.Block || got.Pos != tc.expected.Pos {
+				t.Fatalf("got:%+v, want:%+v", got, tc.expected)
+			}
+
+			if errors.Unwrap(got) == nil {
+				t.Fatalf("cause is missing")
+			}
+		})
+	}
+}
+
 func TestDecodeProgramToStruct_Block(t *testing.T) {
 	type ACL struct {
 		Type      string   `vcl:"type,label"`
@@ -182,8 +413,8 @@ func TestDecodeProgramToStruct_DirectorBlock(t *testing.T) {
 	}
 
 	type Director struct {
-		Type     string     `vcl:"type,label"`
 		Name     string     `vcl:"name,label"`
+		Type     string     `vcl:"type,label"`
 		Quorum   string     `vcl:".quorum"`
 		Retries  int64      `vcl:".retries"`
 		Backends []*Backend `vcl:",flat"`
@@ -202,14 +433,14 @@ func TestDecodeProgramToStruct_DirectorBlock(t *testing.T) {
 			`director my_dir random {
 				.quorum = 50%;
 				.retries = 3;
-			}`, &Root{}, &Root{Directors: []*Director{&Director{Type: "my_dir", Name: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{}}}},
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{}}}},
 		},
 		"with deep director block": {
 			`director my_dir random {
 				.quorum = 50%;
 				.retries = 3;
 				{ .backend = K_backend1; .weight = 1; }
-			}`, &Root{}, &Root{Directors: []*Director{&Director{Type: "my_dir", Name: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{&Backend{Backend: "K_backend1", Weight: 1}}}}},
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{&Backend{Backend: "K_backend1", Weight: 1}}}}},
 		},
 		"with multiple deep director block": {
 			`director my_dir random {
@@ -217,7 +448,7 @@ func TestDecodeProgramToStruct_DirectorBlock(t *testing.T) {
 				.retries = 3;
 				{ .backend = K_backend1; .weight = 1; }
 				{ .backend = E_backend1; .weight = 3; }
-			}`, &Root{}, &Root{Directors: []*Director{&Director{Type: "my_dir", Name: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{&Backend{Backend: "K_backend1", Weight: 1}, &Backend{Backend: "E_backend1", Weight: 3}}}}},
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{&Backend{Backend: "K_backend1", Weight: 1}, &Backend{Backend: "E_backend1", Weight: 3}}}}},
 		},
 	}
 
@@ -241,6 +472,129 @@ func TestDecodeProgramToStruct_DirectorBlock(t *testing.T) {
 	}
 }
 
+func TestDecodeProgramToStruct_DirectorEntries(t *testing.T) {
+	type Backend struct {
+		Backend string `vcl:".backend"`
+		Weight  int64  `vcl:".weight"`
+		ID      string `vcl:".id"`
+	}
+
+	type Director struct {
+		Name     string     `vcl:"name,label"`
+		Type     string     `vcl:"type,label"`
+		Quorum   string     `vcl:".quorum"`
+		Retries  int64      `vcl:".retries"`
+		Key      string     `vcl:".key"`
+		Backends []*Backend `vcl:",entries"`
+	}
+
+	type Root struct {
+		Directors []*Director `vcl:"director,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		val      interface{}
+		expected interface{}
+	}{
+		"with round-robin director": {
+			`director my_dir round-robin {
+				{ .backend = F_backend1; }
+				{ .backend = F_backend2; }
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "round-robin", Backends: []*Backend{&Backend{Backend: "F_backend1"}, &Backend{Backend: "F_backend2"}}}}},
+		},
+		"with random director": {
+			`director my_dir random {
+				.quorum = 50%;
+				.retries = 3;
+				{ .backend = F_backend1; .weight = 2; }
+				{ .backend = F_backend2; .weight = 1; }
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "random", Quorum: "50%", Retries: 3, Backends: []*Backend{&Backend{Backend: "F_backend1", Weight: 2}, &Backend{Backend: "F_backend2", Weight: 1}}}}},
+		},
+		"with hash director": {
+			`director my_dir hash {
+				.quorum = 20%;
+				{ .backend = F_backend1; .weight = 1; }
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "hash", Quorum: "20%", Backends: []*Backend{&Backend{Backend: "F_backend1", Weight: 1}}}}},
+		},
+		"with client director": {
+			`director my_dir client {
+				.quorum = 20%;
+				{ .backend = F_backend1; .weight = 1; }
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "client", Quorum: "20%", Backends: []*Backend{&Backend{Backend: "F_backend1", Weight: 1}}}}},
+		},
+		"with fallback director": {
+			`director my_dir fallback {
+				{ .backend = F_backend1; }
+				{ .backend = F_backend2; }
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "fallback", Backends: []*Backend{&Backend{Backend: "F_backend1"}, &Backend{Backend: "F_backend2"}}}}},
+		},
+		"with chash director": {
+			`director my_dir chash {
+				.key = object;
+				{ .backend = F_backend1; .id = "s1"; }
+				{ .backend = F_backend2; .id = "s2"; }
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "chash", Key: "object", Backends: []*Backend{&Backend{Backend: "F_backend1", ID: "s1"}, &Backend{Backend: "F_backend2", ID: "s2"}}}}},
+		},
+		"without entries": {
+			`director my_dir random {
+				.retries = 3;
+			}`, &Root{}, &Root{Directors: []*Director{&Director{Name: "my_dir", Type: "random", Retries: 3, Backends: []*Backend{}}}},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			root := tc.val
+			val := reflect.ValueOf(root).Elem()
+			errs := decodeProgramToStruct(program, val)
+
+			if len(errs) > 0 {
+				t.Fatalf("decodeProgramToStruct_DirectorEntries has errorr, err:%v", errs)
+			}
+
+			if !reflect.DeepEqual(tc.val, tc.expected) {
+				t.Fatalf("decodeProgramToStruct_DirectorEntries got wrong result, got:%#v, want:%#v", tc.val, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_RawDirectorEntries(t *testing.T) {
+	type Director struct {
+		Name    string                         `vcl:"name,label"`
+		Entries []*schema.DirectorBackendEntry `vcl:",entries"`
+	}
+
+	type Root struct {
+		Directors []*Director `vcl:"director,block"`
+	}
+
+	input := `director my_dir random {
+	{ .backend = F_backend1; .weight = 2; }
+}`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	root := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(root).Elem()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct_RawDirectorEntries has error, err:%v", errs)
+	}
+
+	if len(root.Directors) != 1 || len(root.Directors[0].Entries) != 1 {
+		t.Fatalf("decodeProgramToStruct_RawDirectorEntries got wrong result, got:%#v", root)
+	}
+
+	attr := root.Directors[0].Entries[0].Attributes[".backend"]
+	if attr == nil || attr.Value != "F_backend1" {
+		t.Fatalf("entry .backend attribute wrong, got:%#v, want:%s", attr, "F_backend1")
+	}
+}
+
 func TestDecodeProgramToStruct_TableBlock(t *testing.T) {
 	type Table struct {
 		Type     string `vcl:"type,label"`
@@ -452,7 +806,8 @@ func TestImpliedBodySchema(t *testing.T) {
 
 	type testStruct struct {
 		Type     string     `vcl:"type,label"`
-		Name     string     `vcl:"name"`
+		Name     string     `vcl:"name,required"`
+		Port     int        `vcl:"port"`
 		Resource *testBlock `vcl:"resource,block"`
 	}
 
@@ -471,10 +826,18 @@ func TestImpliedBodySchema(t *testing.T) {
 	}
 
 	for n, tc := range testCases {
-		file := impliedBodySchema(tc.input)
+		file, err := impliedBodySchema(tc.input)
+		if err != nil {
+			t.Fatalf("impliedBodySchema failed[testCase:%d], err:%v", n, err)
+		}
+
 		bs := file.Body.(*schema.BodySchema)
-		if len(bs.Attributes) != 1 {
-			t.Fatalf("Attribute length wrong[testCase:%d], got:%d, want:%d", n, len(bs.Attributes), 1)
+		if len(bs.Attributes) != 2 {
+			t.Fatalf("Attribute length wrong[testCase:%d], got:%d, want:%d", n, len(bs.Attributes), 2)
+		}
+
+		if !bs.Attributes[0].Required || bs.Attributes[1].Required {
+			t.Fatalf("Attribute required wrong[testCase:%d], got:%+v", n, bs.Attributes)
 		}
 
 		if len(bs.Blocks) != 1 {
@@ -494,6 +857,7 @@ func TestGetFieldTags(t *testing.T) {
 		Resource interface{} `vcl:"resource,block"`
 		Flats    interface{} `vcl:",flat"`
 		Comments interface{} `vcl:",comment"`
+		Entries  interface{} `vcl:",entries"`
 	}
 
 	input := &testStruct{
@@ -510,7 +874,10 @@ func TestGetFieldTags(t *testing.T) {
 
 	for n, tc := range testCases {
 		ty := reflect.TypeOf(*tc.input)
-		tags := getFieldTags(ty)
+		tags, err := getFieldTags(ty)
+		if err != nil {
+			t.Fatalf("getFieldTags failed[testCase:%d], err:%v", n, err)
+		}
 
 		if len(tags.Attributes) != 1 {
 			t.Fatalf("Attribute length wrong[testCase:%d], got:%d, want:%d", n, len(tags.Attributes), 1)
@@ -531,5 +898,880 @@ func TestGetFieldTags(t *testing.T) {
 		if len(tags.Comments) != 1 {
 			t.Fatalf("Comments length wrong[testCase:%d], got:%d, want:%d", n, len(tags.Comments), 1)
 		}
+
+		if len(tags.Entries) != 1 {
+			t.Fatalf("Entries length wrong[testCase:%d], got:%d, want:%d", n, len(tags.Entries), 1)
+		}
+	}
+}
+
+type hostPort struct {
+	Host string
+	Port string
+}
+
+func (hp *hostPort) UnmarshalText(text []byte) error {
+	host, port, err := net.SplitHostPort(string(text))
+	if err != nil {
+		return err
+	}
+
+	hp.Host, hp.Port = host, port
+	return nil
+}
+
+type upper string
+
+func (u *upper) UnmarshalText(text []byte) error {
+	*u = upper(strings.ToUpper(string(text)))
+	return nil
+}
+
+type rawTimeout struct {
+	Literal string
+	Pos     token.Position
+}
+
+func (r *rawTimeout) UnmarshalVCL(node interface{}) error {
+	attr, ok := node.(*schema.Attribute)
+	if !ok {
+		return fmt.Errorf("attribute required, got:%T", node)
+	}
+
+	lit, ok := attr.Expr.(*ast.RTimeLiteral)
+	if !ok {
+		return fmt.Errorf("relative time required, got:%T", attr.Expr)
+	}
+
+	r.Literal, r.Pos = lit.Value, attr.Pos
+	return nil
+}
+
+type rawProbe struct {
+	Labels     []string
+	Attributes int
+}
+
+func (r *rawProbe) UnmarshalVCL(node interface{}) error {
+	block, ok := node.(*schema.Block)
+	if !ok {
+		return fmt.Errorf("block required, got:%T", node)
+	}
+
+	r.Labels = block.Labels
+	r.Attributes = len(block.Body.(*schema.BodyContent).Attributes)
+	return nil
+}
+
+func TestDecodeProgramToStruct_Unmarshaler(t *testing.T) {
+	type ACL struct {
+		Endpoints []upper `vcl:",flat"`
+	}
+
+	type Backend struct {
+		Host    *hostPort      `vcl:".host"`
+		Pattern *regexp.Regexp `vcl:".pattern"`
+		Timeout rawTimeout     `vcl:".timeout"`
+	}
+
+	type Root struct {
+		Backend *Backend  `vcl:"backend,block"`
+		Probe   *rawProbe `vcl:"probe,block"`
+		ACLs    []*ACL    `vcl:"acl,block"`
+	}
+
+	input := `backend F_origin {
+	.host = "example.com:443";
+	.pattern = "^/api/";
+	.timeout = 10s;
+}
+
+probe healthcheck {
+	.url = "/";
+	.interval = 5s;
+}
+
+acl local {
+	"localhost";
+}`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	testCases := map[string]struct {
+		got      interface{}
+		expected interface{}
+	}{
+		"text unmarshaler":          {got.Backend.Host, &hostPort{Host: "example.com", Port: "443"}},
+		"standard text unmarshaler": {got.Backend.Pattern.String(), "^/api/"},
+		"attribute unmarshaler":     {got.Backend.Timeout, rawTimeout{Literal: "10s", Pos: token.Position{Offset: 70, Line: 4, Column: 2}}},
+		"block unmarshaler":         {got.Probe, &rawProbe{Labels: []string{"healthcheck"}, Attributes: 2}},
+		"flats text unmarshaler":    {got.ACLs[0].Endpoints, []upper{"LOCALHOST"}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.got, tc.expected) {
+				t.Fatalf("got:%+v, want:%+v", tc.got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_UnmarshalerErrors(t *testing.T) {
+	type Backend struct {
+		Host    hostPort   `vcl:".host"`
+		Timeout rawTimeout `vcl:".timeout"`
+	}
+
+	type Root struct {
+		Backend *Backend `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"with text error": {
+			"backend F_origin {\n\t.host = \"example.com\";\n}",
+			"2:2: Root.Backend.Host (backend F_origin): address example.com: missing port in address",
+		},
+		"with unmarshaler error": {
+			"backend F_origin {\n\t.timeout = \"10s\";\n}",
+			"2:2: Root.Backend.Timeout (backend F_origin): relative time required, got:*ast.StringLiteral",
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem())
+			if len(errs) != 1 {
+				t.Fatalf("errors length wrong, got:%v, want:%d", errs, 1)
+			}
+
+			if errs[0].Error() != tc.expected {
+				t.Fatalf("got:%v, want:%s", errs[0], tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_DisallowUnknownFields(t *testing.T) {
+	type Probe struct {
+		URL string `vcl:".url"`
+	}
+
+	type Backend struct {
+		Name           string `vcl:"name,label"`
+		ConnectTimeout string `vcl:".connect_timeout"`
+		Probe          *Probe `vcl:".probe,block"`
+	}
+
+	type Entry struct {
+		Backend string `vcl:".backend"`
+		Weight  int64  `vcl:".weight"`
+	}
+
+	type Director struct {
+		Name    string   `vcl:"name,label"`
+		Type    string   `vcl:"type,label"`
+		Entries []*Entry `vcl:",entries"`
+	}
+
+	type Root struct {
+		Backends  []*Backend  `vcl:"backend,block"`
+		Directors []*Director `vcl:"director,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected []string
+	}{
+		"with known fields": {
+			"backend F_origin {\n\t.connect_timeout = 1s;\n\t.probe = {\n\t\t.url = \"/\";\n\t}\n}",
+			[]string{},
+		},
+		"with unknown attribute": {
+			"backend F_origin {\n\t.conect_timeout = 1s;\n}",
+			[]string{"2:2: Root.Backends[0] (backend F_origin): unknown attribute .conect_timeout"},
+		},
+		"with unknown attribute in nested block": {
+			"backend F_origin {\n\t.probe = {\n\t\t.uri = \"/\";\n\t}\n}",
+			[]string{"3:3: Root.Backends[0].Probe (.probe): unknown attribute .uri"},
+		},
+		"with unknown block": {
+			"backend F_origin {}\nacl local {}",
+			[]string{"2:1: Root: unknown block acl"},
+		},
+		"with unknown attribute in entry": {
+			"director my_dir random {\n\t{ .backend = F_a; .weight = 1; .wieght = 2; }\n}",
+			[]string{"2:33: Root.Directors[0].Entries[0] (director my_dir random): unknown attribute .wieght"},
+		},
+		"with misspelled block keyword": {
+			"bakend a {\n\t.host = \"x\";\n}",
+			[]string{
+				"1:1: Root: unknown statement bakend",
+				"1:8: Root: unknown statement a",
+				"1:10: Root: unknown entry",
+			},
+		},
+		"with unknown flat": {
+			"backend F_origin {\n\t\"localhost\";\n}",
+			[]string{"2:2: Root.Backends[0] (backend F_origin): unknown flat localhost"},
+		},
+		"with unknown entry": {
+			"backend F_origin {\n\t{ .backend = F_a; }\n}",
+			[]string{"2:2: Root.Backends[0] (backend F_origin): unknown entry"},
+		},
+		"with extra label": {
+			"backend F_origin random {}",
+			[]string{"1:1: Root.Backends[0] (backend F_origin random): backend block has 2 labels but 1 are expected"},
+		},
+		"with multiple errors": {
+			"backend F_origin {\n\t.host = \"example.com\";\n\t.port = \"443\";\n}",
+			[]string{
+				"2:2: Root.Backends[0] (backend F_origin): unknown attribute .host",
+				"3:2: Root.Backends[0] (backend F_origin): unknown attribute .port",
+			},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem(), DisallowUnknownFields())
+
+			got := make([]string, len(errs))
+			for i, err := range errs {
+				got[i] = err.Error()
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got:%q, want:%q", got, tc.expected)
+			}
+
+			if errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem()); len(errs) > 0 {
+				t.Fatalf("unknown fields should be ignored without the option, got:%v", errs)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_TagOptions(t *testing.T) {
+	type Backend struct {
+		Name    string        `vcl:"name,label"`
+		Host    string        `vcl:".host,attr,required"`
+		Port    int           `vcl:".port,default=80"`
+		Timeout time.Duration `vcl:".connect_timeout,attr,default=1s"`
+	}
+
+	type Root struct {
+		Backends []*Backend `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected *Root
+		errs     []string
+	}{
+		"with defaults": {
+			"backend F_origin {\n\t.host = \"example.com\";\n}",
+			&Root{Backends: []*Backend{{Name: "F_origin", Host: "example.com", Port: 80, Timeout: time.Second}}},
+			[]string{},
+		},
+		"with values": {
+			"backend F_origin {\n\t.host = \"example.com\";\n\t.port = \"443\";\n\t.connect_timeout = 5s;\n}",
+			&Root{Backends: []*Backend{{Name: "F_origin", Host: "example.com", Port: 443, Timeout: 5 * time.Second}}},
+			[]string{},
+		},
+		"with missing required": {
+			"backend F_origin {\n\t.port = \"443\";\n}",
+			&Root{Backends: []*Backend{{Name: "F_origin", Port: 443, Timeout: time.Second}}},
+			[]string{"1:1: Root.Backends[0].Host (backend F_origin): missing required attribute .host"},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			got := &Root{}
+			errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem())
+
+			gotErrs := make([]string, len(errs))
+			for i, err := range errs {
+				gotErrs[i] = err.Error()
+			}
+
+			if !reflect.DeepEqual(gotErrs, tc.errs) {
+				t.Fatalf("got:%q, want:%q", gotErrs, tc.errs)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got:%+v, want:%+v", got.Backends[0], tc.expected.Backends[0])
+			}
+		})
+	}
+}
+
+func TestGetFieldTags_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		val      interface{}
+		expected string
+	}{
+		"with invalid kind": {
+			struct {
+				Port int `vcl:".port,unknown"`
+			}{},
+			`invalid vcl field tag kind "unknown" on int "Port"`,
+		},
+		"with invalid option": {
+			struct {
+				Port int `vcl:".port,attr,optional"`
+			}{},
+			`invalid vcl field tag option "optional" on int "Port"`,
+		},
+		"with option on block": {
+			struct {
+				Backend *struct{} `vcl:"backend,block,required"`
+			}{},
+			`vcl field tag options cannot be applied to "block" kind on *struct {} "Backend"`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := getFieldTags(reflect.TypeOf(tc.val))
+			if err == nil {
+				t.Fatalf("getFieldTags should fail")
+			}
+
+			if err.Error() != tc.expected {
+				t.Fatalf("got:%v, want:%s", err, tc.expected)
+			}
+		})
+	}
+}
+
+type validatedBackend struct {
+	Name string `vcl:"name,label"`
+	Port int    `vcl:".port"`
+}
+
+func (b *validatedBackend) Validate() error {
+	if b.Port == 0 || b.Port > 65535 {
+		return fmt.Errorf("port %d is out of range", b.Port)
+	}
+
+	return nil
+}
+
+func TestDecodeProgramToStruct_Validator(t *testing.T) {
+	type Root struct {
+		Backends []*validatedBackend `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected []string
+	}{
+		"with valid":          {"backend F_origin {\n\t.port = 443;\n}", []string{}},
+		"with invalid":        {"backend F_origin {}\n\nbackend F_other {\n\t.port = 70000;\n}", []string{"1:1: Root.Backends[0] (backend F_origin): port 0 is out of range", "3:1: Root.Backends[1] (backend F_other): port 70000 is out of range"}},
+		"with decoding error": {"backend F_origin {\n\t.port = \"https\";\n}", []string{`2:2: Root.Backends[0].Port (backend F_origin): cannot decode "https" into int: integer required`}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem())
+
+			got := make([]string, len(errs))
+			for i, err := range errs {
+				got[i] = err.Error()
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got:%q, want:%q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_BlockMap(t *testing.T) {
+	type Backend struct {
+		Name string `vcl:"name,label"`
+		Port string `vcl:".port"`
+	}
+
+	type Director struct {
+		Name   string `vcl:"name,label"`
+		Type   string `vcl:"type,label"`
+		Quorum string `vcl:".quorum"`
+	}
+
+	type Root struct {
+		Backends  map[string]*Backend             `vcl:"backend,block"`
+		Directors map[string]map[string]*Director `vcl:"director,block"`
+	}
+
+	input := `backend F_origin {
+	.port = "443";
+}
+
+backend F_other {
+	.port = "80";
+}
+
+director my_dir random {
+	.quorum = 50%;
+}
+
+director my_dir fallback {}
+
+director other_dir hash {}`
+
+	expected := &Root{
+		Backends: map[string]*Backend{
+			"F_origin": {Name: "F_origin", Port: "443"},
+			"F_other":  {Name: "F_other", Port: "80"},
+		},
+		Directors: map[string]map[string]*Director{
+			"my_dir": {
+				"random":   {Name: "my_dir", Type: "random", Quorum: "50%"},
+				"fallback": {Name: "my_dir", Type: "fallback"},
+			},
+			"other_dir": {
+				"hash": {Name: "other_dir", Type: "hash"},
+			},
+		},
+	}
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got:%+v, want:%+v", got, expected)
+	}
+}
+
+func TestDecodeProgramToStruct_BlockMapErrors(t *testing.T) {
+	type Backend struct {
+		Name string `vcl:"name,label"`
+	}
+
+	type Director struct {
+		Name string `vcl:"name,label"`
+	}
+
+	type Root struct {
+		Backends  map[string]*Backend             `vcl:"backend,block"`
+		Directors map[string]map[string]*Director `vcl:"director,block"`
+	}
+
+	type ValueMap struct {
+		Backends map[string]Backend `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		val      interface{}
+		expected []string
+	}{
+		"with duplicate label": {
+			"backend F_origin {}\nbackend F_origin {}",
+			&Root{},
+			[]string{`2:1: Root.Backends["F_origin"] (backend F_origin): duplicate backend block F_origin`},
+		},
+		"with duplicate nested labels": {
+			"director my_dir random {}\ndirector my_dir random {}",
+			&Root{},
+			[]string{`2:1: Root.Directors["my_dir"]["random"] (director my_dir random): duplicate director block my_dir random`},
+		},
+		"with missing label": {
+			"director my_dir {}",
+			&Root{},
+			[]string{"1:1: Root.Directors (director my_dir): director block has 1 labels but 2 are required for map[string]map[string]*decoder.Director"},
+		},
+		"with non-pointer value": {
+			"backend F_origin {}",
+			&ValueMap{},
+			[]string{"ValueMap.Backends: vcl 'block' tag kind cannot be applied to map[string]decoder.Backend: map of pointer to struct required"},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			errs := decodeProgramToStruct(program, reflect.ValueOf(tc.val).Elem())
+
+			got := make([]string, len(errs))
+			for i, err := range errs {
+				got[i] = err.Error()
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("got:%q, want:%q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_Remain(t *testing.T) {
+	type Backend struct {
+		Name   string             `vcl:"name,label"`
+		Host   string             `vcl:".host"`
+		Remain schema.BodyContent `vcl:",remain"`
+	}
+
+	type Root struct {
+		Backends   []*Backend          `vcl:"backend,block"`
+		Remain     *schema.BodyContent `vcl:",remain"`
+		Statements []ast.Statement     `vcl:",remain"`
+	}
+
+	input := `# origin
+backend F_origin {
+	.host = "example.com";
+	.port = "443";
+}
+
+acl local {
+	"localhost";
+}
+
+sub vcl_recv {
+	set req.http.X = "1";
+}`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem(), DisallowUnknownFields()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	blockTypes := []string{}
+	for _, block := range got.Remain.Blocks {
+		blockTypes = append(blockTypes, block.Type)
+	}
+
+	attrNames := []string{}
+	for name := range got.Backends[0].Remain.Attributes {
+		attrNames = append(attrNames, name)
+	}
+
+	testCases := map[string]struct {
+		got      interface{}
+		expected interface{}
+	}{
+		"remain blocks":           {blockTypes, []string{"acl", "sub"}},
+		"remain comments":         {got.Remain.Comments, schema.Comments{"origin"}},
+		"remain statements":       {len(got.Statements), 3},
+		"remain first statement":  {got.Statements[0], program.Statements[0]},
+		"remain attributes":       {attrNames, []string{".port"}},
+		"remain block statements": {len(got.Backends[0].Remain.Statements), 1},
+		"claimed attribute":       {got.Backends[0].Host, "example.com"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.got, tc.expected) {
+				t.Fatalf("got:%v, want:%v", tc.got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_RemainErrors(t *testing.T) {
+	type Root struct {
+		Remain map[string]interface{} `vcl:",remain"`
+	}
+
+	l := lexer.NewLexer(`acl local {}`)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem())
+
+	expected := "Root.Remain: vcl 'remain' tag kind cannot be applied to map[string]interface {}: schema.BodyContent or []ast.Statement required"
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Fatalf("got:%v, want:%s", errs, expected)
+	}
+}
+
+func TestDecodeProgramToStruct_Body(t *testing.T) {
+	type Sub struct {
+		Name       string              `vcl:"name,label"`
+		Block      *ast.BlockStatement `vcl:",body"`
+		Statements []ast.Statement     `vcl:",body"`
+		Source     string              `vcl:",body"`
+	}
+
+	type Root struct {
+		Subs []*Sub `vcl:"sub,block"`
+	}
+
+	input := `sub vcl_recv {
+	set req.http.X-Foo = "bar";
+	if (req.url ~ "^/api/") {
+		return(pass);
+	}
+}
+
+sub vcl_deliver {}`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem(), Source([]byte(input))); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	recv := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.BlockExpression).Blocks
+
+	testCases := map[string]struct {
+		got      interface{}
+		expected interface{}
+	}{
+		"block statement": {got.Subs[0].Block, recv},
+		"statements":      {len(got.Subs[0].Statements), 2},
+		"source":          {got.Subs[0].Source, "\n\tset req.http.X-Foo = \"bar\";\n\tif (req.url ~ \"^/api/\") {\n\t\treturn(pass);\n\t}\n"},
+		"empty source":    {got.Subs[1].Source, ""},
+		"empty body":      {len(got.Subs[1].Statements), 0},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.got, tc.expected) {
+				t.Fatalf("got:%v, want:%v", tc.got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_BodyAndRemain(t *testing.T) {
+	type Sub struct {
+		Name       string          `vcl:"name,label"`
+		Statements []ast.Statement `vcl:",body"`
+		Rest       []ast.Statement `vcl:",remain"`
+	}
+
+	type Root struct {
+		Subs []*Sub `vcl:"sub,block"`
+	}
+
+	l := lexer.NewLexer(`sub vcl_recv { set req.url = "/"; }`)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	testCases := map[string]struct {
+		got      interface{}
+		expected interface{}
+	}{
+		"body statements":   {len(got.Subs[0].Statements), 1},
+		"remain statements": {len(got.Subs[0].Rest), 0},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.got, tc.expected) {
+				t.Fatalf("got:%v, want:%v", tc.got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeProgramToStruct_BodyErrors(t *testing.T) {
+	type Source struct {
+		Source string `vcl:",body"`
+	}
+
+	type Invalid struct {
+		Body int `vcl:",body"`
+	}
+
+	type Root struct {
+		Source  *Source  `vcl:"sub,block"`
+		Invalid *Invalid `vcl:"acl,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"without source":    {"sub vcl_recv {}", "1:1: Root.Source.Source (sub vcl_recv): source text is not available"},
+		"with invalid type": {"acl local {}", "1:1: Root.Invalid.Body (acl local): vcl 'body' tag kind cannot be applied to int: *ast.BlockStatement, []ast.Statement or string required"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			errs := decodeProgramToStruct(program, reflect.ValueOf(&Root{}).Elem())
+			if len(errs) != 1 || errs[0].Error() != tc.expected {
+				t.Fatalf("got:%v, want:%s", errs, tc.expected)
+			}
+		})
+	}
+}
+
+type CommonBackendFields struct {
+	Name string `vcl:"name,label"`
+	Host string `vcl:".host"`
+	Port string `vcl:".port"`
+}
+
+type ProbeFields struct {
+	Probe *struct {
+		URL string `vcl:".url"`
+	} `vcl:".probe,block"`
+}
+
+type timeoutFields struct {
+	ConnectTimeout time.Duration `vcl:".connect_timeout"`
+}
+
+func TestDecodeProgramToStruct_Embedded(t *testing.T) {
+	type Backend struct {
+		CommonBackendFields
+		*ProbeFields
+		timeoutFields
+		Port int `vcl:".port"` // shadows the port of CommonBackendFields
+	}
+
+	type Root struct {
+		Backends []*Backend `vcl:"backend,block"`
+	}
+
+	input := `backend F_origin {
+	.host = "example.com";
+	.port = "443";
+	.connect_timeout = 1s;
+	.probe = {
+		.url = "/";
+	}
+}
+
+backend F_other {
+	.host = "example.org";
+}`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem(), DisallowUnknownFields()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	testCases := map[string]struct {
+		got      interface{}
+		expected interface{}
+	}{
+		"embedded label":           {got.Backends[0].Name, "F_origin"},
+		"embedded attribute":       {got.Backends[0].Host, "example.com"},
+		"shadowed attribute":       {got.Backends[0].CommonBackendFields.Port, ""},
+		"shadowing attribute":      {got.Backends[0].Port, 443},
+		"unexported embedded":      {got.Backends[0].ConnectTimeout, time.Second},
+		"pointer to embedded":      {got.Backends[0].Probe.URL, "/"},
+		"nil pointer to embedded":  {got.Backends[1].ProbeFields, (*ProbeFields)(nil)},
+		"other embedded attribute": {got.Backends[1].Host, "example.org"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			if !reflect.DeepEqual(tc.got, tc.expected) {
+				t.Fatalf("got:%v, want:%v", tc.got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetFieldTags_Duplicate(t *testing.T) {
+	type A struct {
+		Host string `vcl:".host"`
+	}
+
+	type B struct {
+		Host string `vcl:".host"`
+	}
+
+	type Backend struct {
+		A
+		B
+	}
+
+	_, err := getFieldTags(reflect.TypeOf(Backend{}))
+	expected := `duplicate vcl attr ".host" on string "Host"`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("got:%v, want:%s", err, expected)
+	}
+}
+
+func TestDecodeProgramToStruct_Interface(t *testing.T) {
+	type Root struct {
+		String   interface{}  `vcl:"string"`
+		Int      interface{}  `vcl:"int"`
+		Bool     interface{}  `vcl:"bool"`
+		RTime    interface{}  `vcl:"rtime"`
+		Percent  interface{}  `vcl:"percent"`
+		Ident    interface{}  `vcl:"ident"`
+		Stringer fmt.Stringer `vcl:"stringer"`
+	}
+
+	input := `string = "example.com"
+int = 3
+bool = true
+rtime = 10s
+percent = 50%
+ident = F_origin
+stringer = 1m`
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	got := &Root{}
+	if errs := decodeProgramToStruct(program, reflect.ValueOf(got).Elem()); len(errs) > 0 {
+		t.Fatalf("decodeProgramToStruct has errors, err:%v", errs)
+	}
+
+	expected := &Root{
+		String:   "example.com",
+		Int:      int64(3),
+		Bool:     true,
+		RTime:    10 * time.Second,
+		Percent:  float64(50),
+		Ident:    "F_origin",
+		Stringer: time.Minute,
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("got:%+v, want:%+v", got, expected)
 	}
 }
@@ -0,0 +1,95 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// DecodeError is an error of decoding the value into the field
+type DecodeError struct {
+	Path  string         // Go field path such as "Root.Backends[2].Probe"
+	Block string         // VCL block type and labels such as "backend F_origin", empty for the root
+	Pos   token.Position // position of the attribute or the block, invalid if it is unknown
+	Err   error          // underlying cause
+}
+
+// Error returns the error message such as `1:1: Root.Backends[0].Port (backend F_origin): ...`
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	if e.Pos.IsValid() {
+		b.WriteString(e.Pos.String() + ": ")
+	}
+
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		if e.Block != "" {
+			b.WriteString(" (" + e.Block + ")")
+		}
+		b.WriteString(": ")
+	}
+
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the underlying cause
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// location is the place of the value which is decoded, and used for the errors
+type location struct {
+	path  string
+	block *schema.Block // the block which is decoded, nil for the root
+}
+
+// field returns the location of the field of the struct
+func (l location) field(name string) location {
+	if l.path != "" {
+		name = l.path + "." + name
+	}
+
+	return location{path: name, block: l.block}
+}
+
+// index returns the location of the element of the slice
+func (l location) index(i int) location {
+	return location{path: fmt.Sprintf("%s[%d]", l.path, i), block: l.block}
+}
+
+// key returns the location of the value of the map
+func (l location) key(k string) location {
+	return location{path: fmt.Sprintf("%s[%q]", l.path, k), block: l.block}
+}
+
+// in returns the location inside the block
+func (l location) in(block *schema.Block) location {
+	return location{path: l.path, block: block}
+}
+
+// errorf returns the error at the location.
+// The position of the block is used if pos is invalid.
+func (l location) errorf(pos token.Position, format string, args ...interface{}) error {
+	return l.wrap(pos, fmt.Errorf(format, args...))
+}
+
+// wrap returns the error at the location with the cause
+func (l location) wrap(pos token.Position, err error) error {
+	e := &DecodeError{
+		Path: l.path,
+		Pos:  pos,
+		Err:  err,
+	}
+
+	if l.block != nil {
+		e.Block = strings.Join(append([]string{l.block.Type}, l.block.Labels...), " ")
+		if !pos.IsValid() {
+			e.Pos = l.block.Pos
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,77 @@
+package decoder
+
+import (
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+)
+
+// decoder holds the options of decoding into a struct
+type decoder struct {
+	disallowUnknownFields bool
+	source                []byte
+}
+
+// Option is an option of Decode
+type Option func(*decoder)
+
+// DisallowUnknownFields makes the attributes, the block types, the labels, the flats, the entries and the other statements which are not in the struct errors
+func DisallowUnknownFields() Option {
+	return func(d *decoder) {
+		d.disallowUnknownFields = true
+	}
+}
+
+// Source sets the source of the program, which is used for the body fields of string
+func Source(src []byte) Option {
+	return func(d *decoder) {
+		d.source = src
+	}
+}
+
+// unknownFields returns the errors of the content which is not claimed by the tags,
+// which are the attributes, the blocks, the flats, the entries and the other statements such as the misspelled keywords.
+// Comments are not fields and the bad statements are reported by the parser.
+func unknownFields(content *schema.BodyContent, tags *fieldTags, loc location) []error {
+	errs := []error{}
+	for _, stmt := range remainContent(content, tags).Statements {
+		switch v := stmt.(type) {
+		case *ast.AssignStatement:
+			if _, ok := v.Value.(*ast.BlockExpression); ok {
+				errs = append(errs, loc.errorf(v.Token.Pos, "unknown block %s", v.TokenLiteral()))
+				continue
+			}
+
+			errs = append(errs, loc.errorf(v.Name.Token.Pos, "unknown attribute %s", v.Name.Value))
+		case *ast.AssignFieldStatement:
+			errs = append(errs, loc.errorf(v.Name.Token.Pos, "unknown attribute %s", v.Name.Value))
+		case *ast.ExpressionStatement:
+			errs = append(errs, unknownExpression(v, loc))
+		case *ast.SetStatement:
+			errs = append(errs, loc.errorf(v.Token.Pos, "unknown statement %s", v.TokenLiteral()))
+		case *ast.UnsetStatement:
+			errs = append(errs, loc.errorf(v.Token.Pos, "unknown statement %s", v.TokenLiteral()))
+		case *ast.ReturnStatement:
+			errs = append(errs, loc.errorf(v.Token.Pos, "unknown statement %s", v.TokenLiteral()))
+		case *ast.CallStatement:
+			errs = append(errs, loc.errorf(v.Token.Pos, "unknown statement %s", v.TokenLiteral()))
+		}
+	}
+
+	return errs
+}
+
+// unknownExpression returns the error of the expression statement which is not claimed by the tags
+func unknownExpression(stmt *ast.ExpressionStatement, loc location) error {
+	switch expr := stmt.Expression.(type) {
+	case *ast.BlockExpression:
+		if expr.TokenLiteral() == "{" {
+			return loc.errorf(expr.Token.Pos, "unknown entry")
+		}
+
+		return loc.errorf(expr.Token.Pos, "unknown block %s", expr.TokenLiteral())
+	case *ast.StringLiteral, *ast.CIDRLiteral, *ast.BooleanLiteral, *ast.IntegerLiteral:
+		return loc.errorf(stmt.Token.Pos, "unknown flat %s", stmt.TokenLiteral())
+	}
+
+	return loc.errorf(stmt.Token.Pos, "unknown statement %s", stmt.TokenLiteral())
+}
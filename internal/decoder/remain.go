@@ -0,0 +1,124 @@
+package decoder
+
+import (
+	"reflect"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+var (
+	contentType    = reflect.TypeOf(schema.BodyContent{})
+	statementsType = reflect.TypeOf([]ast.Statement{})
+)
+
+// decodeRemain sets the content which is not claimed by the other fields to the remain fields.
+// The field can be schema.BodyContent, the pointer to it or []ast.Statement.
+func decodeRemain(content *schema.BodyContent, tags *fieldTags, val reflect.Value, loc location) []error {
+	if len(tags.Remain) == 0 {
+		return nil
+	}
+
+	remain := remainContent(content, tags)
+	errs := []error{}
+	for _, n := range tags.Remain {
+		field := val.Type().FieldByIndex(n.FieldIndex)
+		fieldV := fieldByIndex(val, n.FieldIndex, true)
+
+		switch field.Type {
+		case contentType:
+			fieldV.Set(reflect.ValueOf(*remain))
+		case reflect.PtrTo(contentType):
+			fieldV.Set(reflect.ValueOf(remain))
+		case statementsType:
+			fieldV.Set(reflect.ValueOf(remain.Statements))
+		default:
+			errs = append(errs, loc.field(field.Name).errorf(token.Position{}, "vcl 'remain' tag kind cannot be applied to %s: schema.BodyContent or []ast.Statement required", field.Type.String()))
+		}
+	}
+
+	return errs
+}
+
+// remainContent returns the content which is not claimed by the fields of the tags
+func remainContent(content *schema.BodyContent, tags *fieldTags) *schema.BodyContent {
+	remain := &schema.BodyContent{
+		Attributes: schema.Attributes{},
+		Blocks:     schema.Blocks{},
+		Flats:      schema.Flats{},
+		Comments:   schema.Comments{},
+		Entries:    schema.Entries{},
+		Statements: []ast.Statement{},
+	}
+
+	for name, attr := range content.Attributes {
+		if _, ok := tags.Attributes[name]; !ok {
+			remain.Attributes[name] = attr
+		}
+	}
+
+	for _, block := range content.Blocks {
+		if _, ok := tags.Blocks[block.Type]; !ok {
+			remain.Blocks = append(remain.Blocks, block)
+		}
+	}
+
+	if len(tags.Flats) == 0 {
+		remain.Flats = append(remain.Flats, content.Flats...)
+	}
+
+	if len(tags.Comments) == 0 {
+		remain.Comments = append(remain.Comments, content.Comments...)
+	}
+
+	if len(tags.Entries) == 0 {
+		remain.Entries = append(remain.Entries, content.Entries...)
+	}
+
+	for _, stmt := range content.Statements {
+		if !claimed(stmt, tags) {
+			remain.Statements = append(remain.Statements, stmt)
+		}
+	}
+
+	return remain
+}
+
+// claimed reports whether the statement is decoded into the fields of the tags
+func claimed(stmt ast.Statement, tags *fieldTags) bool {
+	// all statements of the body are decoded into the body fields
+	if len(tags.Bodies) > 0 {
+		return true
+	}
+
+	switch v := stmt.(type) {
+	case *ast.AssignStatement:
+		if _, ok := v.Value.(*ast.BlockExpression); ok {
+			_, ok := tags.Blocks[v.TokenLiteral()]
+			return ok
+		}
+
+		_, ok := tags.Attributes[v.Name.Value]
+		return ok
+	case *ast.AssignFieldStatement:
+		_, ok := tags.Attributes[v.Name.Value]
+		return ok
+	case *ast.ExpressionStatement:
+		switch expr := v.Expression.(type) {
+		case *ast.BlockExpression:
+			if expr.TokenLiteral() == "{" {
+				return len(tags.Flats) > 0 || len(tags.Entries) > 0
+			}
+
+			_, ok := tags.Blocks[expr.TokenLiteral()]
+			return ok
+		case *ast.StringLiteral, *ast.CIDRLiteral, *ast.BooleanLiteral, *ast.IntegerLiteral:
+			return len(tags.Flats) > 0
+		}
+	case *ast.CommentStatement:
+		return len(tags.Comments) > 0
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+package decoder
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Unmarshaler is implemented by the types which decode themselves.
+// node is *schema.Attribute for the attributes, which has the raw AST node as Expr, *schema.Block for the blocks
+// and the value such as "localhost" for the flats.
+type Unmarshaler interface {
+	UnmarshalVCL(node interface{}) error
+}
+
+// Validator is implemented by the structs which validate themselves after the fields are decoded
+type Validator interface {
+	Validate() error
+}
+
+var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+
+// isUnmarshaler reports whether the pointer to the type implements Unmarshaler
+func isUnmarshaler(ty reflect.Type) bool {
+	return reflect.PtrTo(ty).Implements(unmarshalerType)
+}
+
+// unmarshal decodes the node or the value into the new value of the type by Unmarshaler or encoding.TextUnmarshaler.
+// The type can be a pointer to the implementation, and ok is false if the type implements neither of them.
+// net.IP is left to convert for the consistent errors.
+func unmarshal(ty reflect.Type, node interface{}, value interface{}) (v reflect.Value, ok bool, err error) {
+	elem := ty
+	if ty.Kind() == reflect.Ptr {
+		elem = ty.Elem()
+	}
+
+	if elem == ipType {
+		return reflect.Value{}, false, nil
+	}
+
+	ptr := reflect.New(elem)
+	switch u := ptr.Interface().(type) {
+	case Unmarshaler:
+		err = u.UnmarshalVCL(node)
+	case encoding.TextUnmarshaler:
+		text, isText := textOf(value)
+		if !isText {
+			_, err = cannotConvert(value, ty, "text required")
+			return reflect.Value{}, true, err
+		}
+
+		err = u.UnmarshalText([]byte(text))
+	default:
+		return reflect.Value{}, false, nil
+	}
+
+	if err != nil {
+		return reflect.Value{}, true, err
+	}
+
+	if ty.Kind() == reflect.Ptr {
+		return ptr, true, nil
+	}
+
+	return ptr.Elem(), true, nil
+}
+
+// textOf returns the text of the literal value for encoding.TextUnmarshaler
+func textOf(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+
+	return "", false
+}
+
+// validate calls Validate of the struct if it implements Validator
+func validate(val reflect.Value, loc location) []error {
+	if !val.CanAddr() {
+		return nil
+	}
+
+	v, ok := val.Addr().Interface().(Validator)
+	if !ok {
+		return nil
+	}
+
+	if err := v.Validate(); err != nil {
+		return []error{loc.wrap(token.Position{}, err)}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package dialect
+
+import (
+	"sort"
+	"strings"
+)
+
+// Access is the permission of the variables in the subroutine
+type Access int
+
+// NoAccess means the variable cannot be used
+const NoAccess Access = 0
+
+const (
+	// Read means the variable can be read
+	Read Access = 1 << iota
+
+	// Write means the variable can be set and unset
+	Write
+
+	// ReadWrite means the variable can be read, set and unset
+	ReadWrite = Read | Write
+)
+
+// Subroutine is a built-in subroutine which is a state of the request
+type Subroutine struct {
+	Name string
+
+	// Variables is the access to the variable families such as "beresp" in this subroutine.
+	// Families which are not listed cannot be used.
+	Variables map[string]Access
+
+	// Returns are the actions allowed in `return` such as "pass"
+	Returns []string
+}
+
+// Dialect is a flavor of VCL such as Fastly or Varnish
+type Dialect struct {
+	Name        string
+	Subroutines []*Subroutine // in the order of the request flow
+
+	subroutines map[string]*Subroutine
+}
+
+// families are the variable families whose access depends on the subroutine.
+// Other variables such as client.ip can be used in all subroutines.
+var families = map[string]bool{
+	"req":    true,
+	"bereq":  true,
+	"beresp": true,
+	"obj":    true,
+	"resp":   true,
+}
+
+func newDialect(name string, subs ...*Subroutine) *Dialect {
+	d := &Dialect{
+		Name:        name,
+		Subroutines: subs,
+		subroutines: map[string]*Subroutine{},
+	}
+
+	for _, sub := range subs {
+		d.subroutines[sub.Name] = sub
+	}
+
+	return d
+}
+
+// Fastly is the dialect of Fastly VCL
+var Fastly = newDialect("fastly",
+	&Subroutine{Name: "vcl_recv", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"lookup", "pass", "pipe", "error", "restart"}},
+	&Subroutine{Name: "vcl_hash", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"hash"}},
+	&Subroutine{Name: "vcl_hit", Variables: map[string]Access{"req": ReadWrite, "obj": Read}, Returns: []string{"deliver", "deliver_stale", "pass", "error", "restart"}},
+	&Subroutine{Name: "vcl_miss", Variables: map[string]Access{"req": ReadWrite, "bereq": ReadWrite}, Returns: []string{"fetch", "deliver_stale", "pass", "error"}},
+	&Subroutine{Name: "vcl_pass", Variables: map[string]Access{"req": ReadWrite, "bereq": ReadWrite}, Returns: []string{"pass", "error"}},
+	&Subroutine{Name: "vcl_fetch", Variables: map[string]Access{"req": ReadWrite, "bereq": Read, "beresp": ReadWrite}, Returns: []string{"deliver", "deliver_stale", "pass", "error", "restart"}},
+	&Subroutine{Name: "vcl_error", Variables: map[string]Access{"req": ReadWrite, "obj": ReadWrite}, Returns: []string{"deliver", "deliver_stale", "restart"}},
+	&Subroutine{Name: "vcl_deliver", Variables: map[string]Access{"req": ReadWrite, "obj": Read, "resp": ReadWrite}, Returns: []string{"deliver", "restart"}},
+	&Subroutine{Name: "vcl_log", Variables: map[string]Access{"req": Read, "resp": Read}, Returns: []string{"deliver"}},
+)
+
+// Varnish is the dialect of Varnish Cache 4.0 and later
+var Varnish = newDialect("varnish",
+	&Subroutine{Name: "vcl_init", Variables: map[string]Access{}, Returns: []string{"ok", "fail"}},
+	&Subroutine{Name: "vcl_recv", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"hash", "pass", "pipe", "synth", "purge", "restart", "vcl", "fail"}},
+	&Subroutine{Name: "vcl_pipe", Variables: map[string]Access{"req": ReadWrite, "bereq": ReadWrite}, Returns: []string{"pipe", "synth", "fail"}},
+	&Subroutine{Name: "vcl_pass", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"fetch", "synth", "restart", "fail"}},
+	&Subroutine{Name: "vcl_hash", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"lookup", "fail"}},
+	&Subroutine{Name: "vcl_purge", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"synth", "restart", "fail"}},
+	&Subroutine{Name: "vcl_hit", Variables: map[string]Access{"req": ReadWrite, "obj": Read}, Returns: []string{"deliver", "pass", "synth", "restart", "fail"}},
+	&Subroutine{Name: "vcl_miss", Variables: map[string]Access{"req": ReadWrite}, Returns: []string{"fetch", "pass", "synth", "restart", "fail"}},
+	&Subroutine{Name: "vcl_deliver", Variables: map[string]Access{"req": ReadWrite, "obj": Read, "resp": ReadWrite}, Returns: []string{"deliver", "synth", "restart", "fail"}},
+	&Subroutine{Name: "vcl_synth", Variables: map[string]Access{"req": ReadWrite, "resp": ReadWrite}, Returns: []string{"deliver", "restart", "fail"}},
+	&Subroutine{Name: "vcl_backend_fetch", Variables: map[string]Access{"bereq": ReadWrite}, Returns: []string{"fetch", "error", "abandon", "fail"}},
+	&Subroutine{Name: "vcl_backend_response", Variables: map[string]Access{"bereq": ReadWrite, "beresp": ReadWrite}, Returns: []string{"deliver", "retry", "error", "abandon", "fail"}},
+	&Subroutine{Name: "vcl_backend_error", Variables: map[string]Access{"bereq": ReadWrite, "beresp": ReadWrite}, Returns: []string{"deliver", "retry", "fail"}},
+	&Subroutine{Name: "vcl_fini", Variables: map[string]Access{}, Returns: []string{"ok"}},
+)
+
+// dialects are the supported dialects by name
+var dialects = map[string]*Dialect{
+	Fastly.Name:  Fastly,
+	Varnish.Name: Varnish,
+}
+
+// Default is the dialect used when none is specified
+var Default = Fastly
+
+// Lookup returns the dialect by the name such as "fastly"
+func Lookup(name string) (*Dialect, bool) {
+	d, ok := dialects[strings.ToLower(name)]
+	return d, ok
+}
+
+// Names returns the names of the supported dialects
+func Names() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Subroutine returns the built-in subroutine by the name, nil if the dialect does not have it
+func (d *Dialect) Subroutine(name string) *Subroutine {
+	return d.subroutines[name]
+}
+
+// Access returns the access to the variable in the subroutine.
+// Variables which do not belong to the families like req.* are always readable and writable.
+func (s *Subroutine) Access(variable string) Access {
+	i := strings.Index(variable, ".")
+	if i < 0 || !families[variable[:i]] {
+		return ReadWrite
+	}
+
+	return s.Variables[variable[:i]]
+}
+
+// AllowsReturn reports whether the action is allowed in `return` of the subroutine
+func (s *Subroutine) AllowsReturn(action string) bool {
+	for _, r := range s.Returns {
+		if r == action {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package dialect
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	testCases := map[string]struct {
+		name     string
+		expected *Dialect
+	}{
+		"with fastly":          {"fastly", Fastly},
+		"with upper case":      {"Varnish", Varnish},
+		"with unknown dialect": {"apache", nil},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			d, ok := Lookup(tc.name)
+			if ok != (tc.expected != nil) || d != tc.expected {
+				t.Fatalf("lookup failed, got:%v, want:%v", d, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSubroutine_Access(t *testing.T) {
+	testCases := map[string]struct {
+		dialect  *Dialect
+		sub      string
+		variable string
+		expected Access
+	}{
+		"with writable family":   {Fastly, "vcl_fetch", "beresp.ttl", ReadWrite},
+		"with read-only family":  {Fastly, "vcl_fetch", "bereq.url", Read},
+		"with inaccessible":      {Fastly, "vcl_recv", "beresp.ttl", NoAccess},
+		"with global variable":   {Fastly, "vcl_recv", "client.ip", ReadWrite},
+		"with varnish":           {Varnish, "vcl_backend_fetch", "req.url", NoAccess},
+		"with identifier no dot": {Varnish, "vcl_recv", "req", ReadWrite},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sub := tc.dialect.Subroutine(tc.sub)
+			if sub == nil {
+				t.Fatalf("subroutine %s not found", tc.sub)
+			}
+
+			if access := sub.Access(tc.variable); access != tc.expected {
+				t.Fatalf("access failed, got:%v, want:%v", access, tc.expected)
+			}
+		})
+	}
+}
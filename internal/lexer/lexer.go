@@ -12,23 +12,39 @@ type Lexer struct {
 	pos     int
 	readPos int
 	char    byte
+
+	line   int
+	column int
 }
 
 // NewLexer returns the lexer with givin string input
 func NewLexer(input string) *Lexer {
 	l := &Lexer{
 		input: input,
+		line:  1,
 	}
 	l.init()
 	return l
 }
 
+// Input returns the input which the lexer reads
+func (l *Lexer) Input() string {
+	return l.input
+}
+
 func (l *Lexer) init() {
 	l.readChar()
 }
 
 // readChar retrieves the byte from readPos
 func (l *Lexer) readChar() {
+	if l.char == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
 	if l.readPos >= len(l.input) {
 		l.char = 0
 	} else {
@@ -41,7 +57,7 @@ func (l *Lexer) readChar() {
 // readIndentifier reads the indentifier
 func (l *Lexer) readIndentifier() string {
 	pos := l.pos
-	for isLetter(l.char) || isDigit(l.char) {
+	for isLetter(l.char) || isDigit(l.char) || isHyphen(l.char) {
 		l.readChar()
 	}
 	return l.input[pos:l.pos]
@@ -55,18 +71,26 @@ func (l *Lexer) readNumber() string {
 	return l.input[pos:l.pos]
 }
 
-func (l *Lexer) readString() string {
+// readString reads the string until the closing quote.
+// It reports false if the string is not closed before the end of the line.
+func (l *Lexer) readString() (string, bool) {
 	pos := l.pos + 1
-	for l.char != 0 && l.char != ';' {
+	for {
 		l.readChar()
-		if l.char == '"' {
-			if l.peekChar() != '/' {
-				break
-			}
+		switch {
+		case l.char == 0, isNewLine(l.char):
+			return l.input[pos:l.pos], false
+		case l.char == '"':
+			return l.input[pos:l.pos], true
 		}
 	}
+}
 
-	return l.input[pos:l.pos]
+// readCIDR reads the mask of the CIDR like "35.0.0.0"/24 after the closing quote of the address
+func (l *Lexer) readCIDR(addr string) string {
+	l.readChar() // closing quote
+	l.readChar() // slash
+	return "\"" + addr + "\"/" + l.readNumber()
 }
 
 func (l *Lexer) readPercentage(number string) string {
@@ -74,9 +98,46 @@ func (l *Lexer) readPercentage(number string) string {
 	return number + "%"
 }
 
+// durationUnits are the units of the relative time like 10s
+var durationUnits = map[string]bool{
+	"ms": true,
+	"s":  true,
+	"m":  true,
+	"h":  true,
+	"d":  true,
+	"y":  true,
+}
+
+// readDuration reads the unit of the relative time after the number.
+// It returns false without reading if the following letters are not a unit.
+func (l *Lexer) readDuration(number string) (string, bool) {
+	end := l.pos
+	for end < len(l.input) && 'a' <= l.input[end] && l.input[end] <= 'z' {
+		end++
+	}
+
+	if end < len(l.input) && (isLetter(l.input[end]) || isDigit(l.input[end])) {
+		return "", false
+	}
+
+	unit := l.input[l.pos:end]
+	if !durationUnits[unit] {
+		return "", false
+	}
+
+	for l.pos < end {
+		l.readChar()
+	}
+
+	return number + unit, true
+}
+
 func (l *Lexer) readCommentLine() string {
 	l.readChar()
-	pos := l.pos + 1 // Memo(KeisukeYamashita): Remove the first white space
+	pos := l.pos
+	if l.char == ' ' {
+		pos++ // Memo(KeisukeYamashita): Remove the first white space
+	}
 	for !isNewLine(l.char) {
 		l.readChar()
 		if l.char == 0 {
@@ -84,7 +145,17 @@ func (l *Lexer) readCommentLine() string {
 		}
 	}
 
-	return l.input[pos:l.pos]
+	// the position can be past the end of the input when the comment is at the end
+	end := l.pos
+	if end > len(l.input) {
+		end = len(l.input)
+	}
+
+	if pos > end {
+		return ""
+	}
+
+	return l.input[pos:end]
 }
 
 func (l *Lexer) peekChar() byte {
@@ -95,6 +166,19 @@ func (l *Lexer) peekChar() byte {
 	return l.input[l.readPos]
 }
 
+// peekCharsAre reports whether the input after the current char starts with s
+func (l *Lexer) peekCharsAre(s string) bool {
+	return l.readPos <= len(l.input) && strings.HasPrefix(l.input[l.readPos:], s)
+}
+
+// readOperator reads the rest of the operator which starts with the current char
+func (l *Lexer) readOperator(literal string) string {
+	for i := 1; i < len(literal); i++ {
+		l.readChar()
+	}
+	return literal
+}
+
 func (l *Lexer) curCharIs(b byte) bool {
 	return l.char == b
 }
@@ -109,10 +193,26 @@ func (l *Lexer) eatWhiteSpace() {
 	}
 }
 
-// NextToken ...
+// position returns the position of the current char
+func (l *Lexer) position() token.Position {
+	return token.Position{
+		Offset: l.pos,
+		Line:   l.line,
+		Column: l.column,
+	}
+}
+
+// NextToken returns the next token with its position in the input
 func (l *Lexer) NextToken() token.Token {
 	l.eatWhiteSpace()
 
+	pos := l.position()
+	tok := l.nextToken()
+	tok.Pos = pos
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
 	tok := token.Token{}
 	switch l.char {
 	case '=':
@@ -145,6 +245,10 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(char) + string(l.char)
 			tok = token.Token{Type: token.LMULTICOMMENTLINE, Literal: literal}
+		} else if l.peekCharIs('=') {
+			tok = token.Token{Type: token.DIVASSIGN, Literal: l.readOperator("/=")}
+		} else {
+			tok = token.NewToken(token.SLASH, l.char)
 		}
 	case '*':
 		if l.peekCharIs('/') {
@@ -152,6 +256,44 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := string(char) + string(l.char)
 			tok = token.Token{Type: token.RMULTICOMMENTLINE, Literal: literal}
+		} else if l.peekCharIs('=') {
+			tok = token.Token{Type: token.MULASSIGN, Literal: l.readOperator("*=")}
+		} else {
+			tok = token.NewToken(token.ASTERISK, l.char)
+		}
+	case '%':
+		if l.peekCharIs('=') {
+			tok = token.Token{Type: token.MODASSIGN, Literal: l.readOperator("%=")}
+		} else {
+			tok = token.NewToken(token.PERCENT, l.char)
+		}
+	case '^':
+		if l.peekCharIs('=') {
+			tok = token.Token{Type: token.BITXORASSIGN, Literal: l.readOperator("^=")}
+		} else {
+			tok = token.NewToken(token.ILLEGAL, l.char)
+		}
+	case '<':
+		if l.peekCharsAre("<=") {
+			tok = token.Token{Type: token.LSHIFTASSIGN, Literal: l.readOperator("<<=")}
+		} else if l.peekCharIs('=') {
+			tok = token.Token{Type: token.LE, Literal: l.readOperator("<=")}
+		} else {
+			tok = token.NewToken(token.LT, l.char)
+		}
+	case '>':
+		if l.peekCharsAre(">=") {
+			tok = token.Token{Type: token.RSHIFTASSIGN, Literal: l.readOperator(">>=")}
+		} else if l.peekCharIs('=') {
+			tok = token.Token{Type: token.GE, Literal: l.readOperator(">=")}
+		} else {
+			tok = token.NewToken(token.GT, l.char)
+		}
+	case '-':
+		if l.peekCharIs('=') {
+			tok = token.Token{Type: token.SUBASSIGN, Literal: l.readOperator("-=")}
+		} else {
+			tok = token.NewToken(token.MINUS, l.char)
 		}
 	case '(':
 		tok = token.NewToken(token.LPAREN, l.char)
@@ -162,33 +304,59 @@ func (l *Lexer) NextToken() token.Token {
 	case '}':
 		tok = token.NewToken(token.RBRACE, l.char)
 	case '!':
-		tok = token.NewToken(token.BANG, l.char)
+		if l.peekCharIs('=') {
+			char := l.char
+			l.readChar()
+			literal := string(char) + string(l.char)
+			tok = token.Token{Type: token.NOTEQUAL, Literal: literal}
+		} else if l.peekCharIs('~') {
+			char := l.char
+			l.readChar()
+			literal := string(char) + string(l.char)
+			tok = token.Token{Type: token.NOTMATCH, Literal: literal}
+		} else {
+			tok = token.NewToken(token.BANG, l.char)
+		}
 	case '+':
-		tok = token.NewToken(token.PLUS, l.char)
+		if l.peekCharIs('=') {
+			tok = token.Token{Type: token.ADDASSIGN, Literal: l.readOperator("+=")}
+		} else {
+			tok = token.NewToken(token.PLUS, l.char)
+		}
 	case '"':
-		s := l.readString()
-		if strings.Contains(s, "/") {
+		s, ok := l.readString()
+		if !ok {
+			tok.Type = token.ILLEGAL
+			tok.Literal = "\"" + s // the leading quote tells the parser that the string is not terminated
+		} else if l.peekCharIs('/') {
 			tok.Type = token.CIDR
-			s = "\"" + s // CIDR format is "35.0.0.0"/24 which we have to wrap by ".
+			tok.Literal = l.readCIDR(s)
+			return tok // early return not to walk step
 		} else {
 			tok.Type = token.STRING
+			tok.Literal = s
 		}
-		tok.Literal = s
 	case '|':
-		// it will be always ||
-		if l.peekChar() == '|' {
+		if l.peekCharsAre("|=") {
+			tok = token.Token{Type: token.ORASSIGN, Literal: l.readOperator("||=")}
+		} else if l.peekChar() == '|' {
 			char := l.char
 			l.readChar()
 			literal := string(char) + string(char)
 			tok = token.Token{Type: token.OR, Literal: literal}
+		} else if l.peekCharIs('=') {
+			tok = token.Token{Type: token.BITORASSIGN, Literal: l.readOperator("|=")}
 		}
 	case '&':
-		// it will be always &&
-		if l.peekChar() == '&' {
+		if l.peekCharsAre("&=") {
+			tok = token.Token{Type: token.ANDASSIGN, Literal: l.readOperator("&&=")}
+		} else if l.peekChar() == '&' {
 			char := l.char
 			l.readChar()
 			literal := string(char) + string(char)
 			tok = token.Token{Type: token.AND, Literal: literal}
+		} else if l.peekCharIs('=') {
+			tok = token.Token{Type: token.BITANDASSIGN, Literal: l.readOperator("&=")}
 		}
 	case 0:
 		tok.Type = token.EOF
@@ -206,6 +374,12 @@ func (l *Lexer) NextToken() token.Token {
 				return tok
 			}
 
+			if literal, ok := l.readDuration(number); ok {
+				tok.Type = token.RTIME
+				tok.Literal = literal
+				return tok
+			}
+
 			tok.Literal = number
 			tok.Type = token.INT
 			return tok // early return not to walk step
@@ -226,6 +400,12 @@ func isDigit(char byte) bool {
 	return '0' <= char && char <= '9'
 }
 
+// isHyphen is used for identifiers such as "round-robin" or "req.http.X-Forwarded-For".
+// It cannot be the first character of the identifier.
+func isHyphen(char byte) bool {
+	return char == '-'
+}
+
 func isNewLine(char byte) bool {
 	return char == '\n'
 }
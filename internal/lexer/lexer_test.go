@@ -15,7 +15,7 @@ func TestNextToken(t *testing.T) {
 		}
 	}{
 		{
-			`=~,; call == && || 10 "keke" false ! "35.0.0.0"/23; server1 K_backend1 50% table`,
+			`=~,; call == && || 10 "keke" false ! "35.0.0.0"/23; server1 K_backend1 50% table != !~`,
 			[]struct {
 				expectedType    token.Type
 				expectedLiteral string
@@ -33,10 +33,13 @@ func TestNextToken(t *testing.T) {
 				{token.FALSE, "false"},
 				{token.BANG, "!"},
 				{token.CIDR, "\"35.0.0.0\"/23"},
+				{token.SEMICOLON, ";"},
 				{token.IDENT, "server1"},
 				{token.IDENT, "K_backend1"},
 				{token.PERCENTAGE, "50%"},
 				{token.TABLE, "table"},
+				{token.NOTEQUAL, "!="},
+				{token.NOTMATCH, "!~"},
 			},
 		},
 		{
@@ -114,6 +117,139 @@ func TestNextToken(t *testing.T) {
 				{token.RBRACE, "}"},
 			},
 		},
+		{
+			`director my_dir round-robin {
+				{ .backend = F_backend1; }
+			}
+			req.http.X-Forwarded-For`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.DIRECTOR, "director"},
+				{token.IDENT, "my_dir"},
+				{token.IDENT, "round-robin"},
+				{token.LBRACE, "{"},
+				{token.LBRACE, "{"},
+				{token.IDENT, ".backend"},
+				{token.ASSIGN, "="},
+				{token.IDENT, "F_backend1"},
+				{token.SEMICOLON, ";"},
+				{token.RBRACE, "}"},
+				{token.RBRACE, "}"},
+				{token.IDENT, "req.http.X-Forwarded-For"},
+			},
+		},
+		{
+			`set unset probe += -= *= /= %= |= &= ^= <<= >>= &&= ||= ror=`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.IDENT, "set"},
+				{token.IDENT, "unset"},
+				{token.IDENT, "probe"},
+				{token.ADDASSIGN, "+="},
+				{token.SUBASSIGN, "-="},
+				{token.MULASSIGN, "*="},
+				{token.DIVASSIGN, "/="},
+				{token.MODASSIGN, "%="},
+				{token.BITORASSIGN, "|="},
+				{token.BITANDASSIGN, "&="},
+				{token.BITXORASSIGN, "^="},
+				{token.LSHIFTASSIGN, "<<="},
+				{token.RSHIFTASSIGN, ">>="},
+				{token.ANDASSIGN, "&&="},
+				{token.ORASSIGN, "||="},
+				{token.IDENT, "ror"},
+				{token.ASSIGN, "="},
+			},
+		},
+		{
+			`"^/foo/bar" "max-age=0; private";`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.STRING, "^/foo/bar"},
+				{token.STRING, "max-age=0; private"},
+				{token.SEMICOLON, ";"},
+			},
+		},
+		{
+			`("10.0.0.0"/8) "10.0.0.0" / 8`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.LPAREN, "("},
+				{token.CIDR, "\"10.0.0.0\"/8"},
+				{token.RPAREN, ")"},
+				{token.STRING, "10.0.0.0"},
+			},
+		},
+		{
+			"\"not terminated;\nx \"eof",
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.ILLEGAL, "\"not terminated;"},
+				{token.IDENT, "x"},
+				{token.ILLEGAL, "\"eof"},
+				{token.EOF, ""},
+			},
+		},
+		{
+			`< > <= >= - * / % <<= /* */ // comment`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.LT, "<"},
+				{token.GT, ">"},
+				{token.LE, "<="},
+				{token.GE, ">="},
+				{token.MINUS, "-"},
+				{token.ASTERISK, "*"},
+				{token.SLASH, "/"},
+				{token.PERCENT, "%"},
+				{token.LSHIFTASSIGN, "<<="},
+				{token.LMULTICOMMENTLINE, "/*"},
+				{token.RMULTICOMMENTLINE, "*/"},
+				{token.COMMENTLINE, "comment"},
+			},
+		},
+		{
+			`X-Cache -1`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.IDENT, "X-Cache"},
+				{token.MINUS, "-"},
+				{token.INT, "1"},
+			},
+		},
+		{
+			`10s 500ms 2m 1h 7d 1y 10sec 3 -1s`,
+			[]struct {
+				expectedType    token.Type
+				expectedLiteral string
+			}{
+				{token.RTIME, "10s"},
+				{token.RTIME, "500ms"},
+				{token.RTIME, "2m"},
+				{token.RTIME, "1h"},
+				{token.RTIME, "7d"},
+				{token.RTIME, "1y"},
+				{token.INT, "10"},
+				{token.IDENT, "sec"},
+				{token.INT, "3"},
+				{token.MINUS, "-"},
+				{token.RTIME, "1s"},
+			},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -131,3 +267,36 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextToken_Position(t *testing.T) {
+	input := `sub vcl_recv {
+	set req.http.X = "a";
+}`
+
+	expectedPositions := []struct {
+		expectedLiteral string
+		expectedPos     token.Position
+	}{
+		{"sub", token.Position{Offset: 0, Line: 1, Column: 1}},
+		{"vcl_recv", token.Position{Offset: 4, Line: 1, Column: 5}},
+		{"{", token.Position{Offset: 13, Line: 1, Column: 14}},
+		{"set", token.Position{Offset: 16, Line: 2, Column: 2}},
+		{"req.http.X", token.Position{Offset: 20, Line: 2, Column: 6}},
+		{"=", token.Position{Offset: 31, Line: 2, Column: 17}},
+		{"a", token.Position{Offset: 33, Line: 2, Column: 19}},
+		{";", token.Position{Offset: 36, Line: 2, Column: 22}},
+		{"}", token.Position{Offset: 38, Line: 3, Column: 1}},
+	}
+
+	l := NewLexer(input)
+	for i, expected := range expectedPositions {
+		tok := l.NextToken()
+		if tok.Literal != expected.expectedLiteral {
+			t.Fatalf("failed[%d] - wrong literal, want: %s, got: %s", i, expected.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos != expected.expectedPos {
+			t.Fatalf("failed[%d] - wrong position of %s, want: %#v, got: %#v", i, tok.Literal, expected.expectedPos, tok.Pos)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// ignoreDirective is the prefix of the comment which suppresses diagnostics such as `# vcllint:ignore unused`
+const ignoreDirective = "vcllint:ignore"
+
+// Rule is a lint rule which inspects the program
+type Rule interface {
+	// ID returns the identifier of the rule which is used for the suppression
+	ID() string
+
+	// Check inspects the program and reports diagnostics to the pass
+	Check(pass *Pass)
+}
+
+// Diagnostic is a problem reported by the rule
+type Diagnostic struct {
+	RuleID  string
+	Pos     token.Position
+	Message string
+}
+
+// String returns the diagnostic such as "2:5: message (rule-id)"
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s (%s)", d.Pos, d.Message, d.RuleID)
+}
+
+// Pass is an input of the rule
+type Pass struct {
+	Program *ast.Program
+	Symbols *resolve.SymbolTable
+	Dialect *dialect.Dialect
+
+	rule        Rule
+	diagnostics []*Diagnostic
+}
+
+// Reportf reports a diagnostic at the position
+func (p *Pass) Reportf(pos token.Position, format string, args ...interface{}) {
+	p.diagnostics = append(p.diagnostics, &Diagnostic{
+		RuleID:  p.rule.ID(),
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Linter runs the rules over the program
+type Linter struct {
+	Rules   []Rule
+	Dialect *dialect.Dialect
+}
+
+// NewLinter returns a linter with the rules for the default dialect
+func NewLinter(rules ...Rule) *Linter {
+	return &Linter{
+		Rules:   rules,
+		Dialect: dialect.Default,
+	}
+}
+
+// Lint runs all rules and returns the diagnostics sorted by the position.
+// Diagnostics suppressed by the ignore comments are dropped.
+func (l *Linter) Lint(program *ast.Program) []*Diagnostic {
+	symbols, _ := resolve.Resolve(program)
+
+	diagnostics := []*Diagnostic{}
+	for _, rule := range l.Rules {
+		pass := &Pass{
+			Program:     program,
+			Symbols:     symbols,
+			Dialect:     l.Dialect,
+			rule:        rule,
+			diagnostics: []*Diagnostic{},
+		}
+
+		rule.Check(pass)
+		diagnostics = append(diagnostics, pass.diagnostics...)
+	}
+
+	ignores := collectIgnores(program)
+	ret := []*Diagnostic{}
+	for _, d := range diagnostics {
+		if ignores.suppressed(d) {
+			continue
+		}
+
+		ret = append(ret, d)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Pos.Line != ret[j].Pos.Line {
+			return ret[i].Pos.Line < ret[j].Pos.Line
+		}
+
+		return ret[i].Pos.Column < ret[j].Pos.Column
+	})
+
+	return ret
+}
+
+// ignores holds the rule IDs suppressed by line.
+// An empty rule ID suppresses every rule.
+type ignores map[int][]string
+
+func (ig ignores) suppressed(d *Diagnostic) bool {
+	for _, id := range ig[d.Pos.Line] {
+		if id == "" || id == d.RuleID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collectIgnores finds the ignore comments.
+// The comment suppresses the diagnostics on the same line and the next line.
+func collectIgnores(program *ast.Program) ignores {
+	ig := ignores{}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		stmt, ok := node.(*ast.CommentStatement)
+		if !ok {
+			return true
+		}
+
+		if stmt.Token.Type != token.HASH && stmt.Token.Type != token.COMMENTLINE {
+			return true
+		}
+
+		ids, ok := parseIgnoreDirective(stmt.Value)
+		if !ok {
+			return true
+		}
+
+		line := stmt.Token.Pos.Line
+		ig[line] = append(ig[line], ids...)
+		ig[line+1] = append(ig[line+1], ids...)
+		return true
+	})
+
+	return ig
+}
+
+// parseIgnoreDirective parses the comment such as "vcllint:ignore unused, empty-if"
+func parseIgnoreDirective(comment string) ([]string, bool) {
+	comment = strings.TrimSpace(comment)
+	if !strings.HasPrefix(comment, ignoreDirective) {
+		return nil, false
+	}
+
+	rest := strings.TrimPrefix(comment, ignoreDirective)
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+
+	ids := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r'
+	})
+
+	if len(ids) == 0 {
+		return []string{""}, true
+	}
+
+	return ids, true
+}
@@ -0,0 +1,165 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func lint(t *testing.T, input string, rules ...Rule) []*Diagnostic {
+	t.Helper()
+
+	l := lexer.NewLexer(input)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser has errors, errs:%v", p.Errors())
+	}
+
+	return NewLinter(rules...).Lint(program)
+}
+
+func TestRules(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		rule     Rule
+		expected []string
+	}{
+		"with unused acl": {`acl purge_ip {
+	"localhost";
+}`, &UnusedRule{}, []string{"1:1: acl purge_ip is declared but not used (unused)"}},
+		"with used acl": {`acl purge_ip {
+	"localhost";
+}
+
+sub vcl_recv {
+	if (client.ip ~ purge_ip) {
+		return (purge);
+	}
+}`, &UnusedRule{}, []string{}},
+		"with unused backend": {`backend F_default {}
+backend F_unused {}`, &UnusedRule{}, []string{"2:1: backend F_unused is declared but not used (unused)"}},
+		"with unused table and sub": {`table redirects {}
+sub helper {}
+sub vcl_recv {}`, &UnusedRule{}, []string{"1:1: table redirects is declared but not used (unused)", "2:1: sub helper is declared but not used (unused)"}},
+		"with set on read-only variable": {`sub vcl_recv {
+	set client.ip = "127.0.0.1";
+	unset req.xid;
+	set req.http.X-Ip = client.ip;
+}`, &ReadOnlyRule{}, []string{"2:6: client.ip is read-only (readonly-variable)", "3:8: req.xid is read-only (readonly-variable)"}},
+		"with invalid regex": {`sub vcl_recv {
+	if (req.url ~ "^/(foo") {}
+	if (req.url !~ "^/foo$") {}
+}`, &RegexRule{}, []string{"2:17: invalid regex \"^/(foo\": error parsing regexp: missing closing ): `^/(foo` (invalid-regex)"}},
+		"with pcre regex": {`sub vcl_recv {
+	if (req.url ~ "^/(?!admin)") {}
+}`, &PCRERegexRule{}, []string{"2:19: regex \"^/(?!admin)\" uses PCRE-only negative lookahead which cannot be verified (pcre-regex)"}},
+		"with empty if": {`sub vcl_recv {
+	if (req.url ~ "^/foo") {
+		# nothing to do
+	} else {
+	}
+	if (req.url ~ "^/bar") {
+		set req.http.X = "1";
+	}
+}`, &EmptyIfRule{}, []string{"2:2: empty if body (empty-if)", "4:9: empty else body (empty-if)"}},
+		"with duplicate set": {`sub vcl_recv {
+	set req.http.X-Foo = "a";
+	set req.http.x-foo = "b";
+	set req.http.X-Bar = "a";
+	set req.http.X-Bar = req.http.X-Bar + "b";
+}`, &DuplicateSetRule{}, []string{"3:6: req.http.x-foo is set twice, previous set at 2:6 (duplicate-set)"}},
+		"with call between sets": {`sub vcl_recv {
+	set req.http.X-Foo = "a";
+	call log_foo;
+	set req.http.X-Foo = "b";
+}`, &DuplicateSetRule{}, []string{}},
+		"with compound set": {`sub vcl_recv {
+	set req.http.X-Foo = "a";
+	set req.http.X-Foo += "b";
+}`, &DuplicateSetRule{}, []string{}},
+		"with missing return": {`sub vcl_recv {
+	set req.http.X = "a";
+}`, &MissingReturnRule{}, []string{"1:1: vcl_recv does not end with return (missing-return)"}},
+		"with return": {`sub vcl_recv {
+	set req.http.X = "a";
+	return (lookup);
+	# end
+}`, &MissingReturnRule{}, []string{}},
+		"with return in all branches": {`sub vcl_recv {
+	if (req.url ~ "^/foo") {
+		return (pass);
+	} else if (req.url ~ "^/bar") {
+		return (pipe);
+	} else {
+		return (lookup);
+	}
+}`, &MissingReturnRule{}, []string{}},
+		"with return in some branches": {`sub vcl_recv {
+	if (req.url ~ "^/foo") {
+		return (pass);
+	} else if (req.url ~ "^/bar") {
+		set req.http.X = "a";
+	} else {
+		return (lookup);
+	}
+}`, &MissingReturnRule{}, []string{"1:1: vcl_recv does not end with return (missing-return)"}},
+		"with if without else": {`sub vcl_recv {
+	if (req.url ~ "^/foo") {
+		return (pass);
+	}
+}`, &MissingReturnRule{}, []string{"1:1: vcl_recv does not end with return (missing-return)"}},
+		"with variable out of scope": {`sub vcl_recv {
+	set beresp.ttl = 10s;
+}`, &ScopeRule{}, []string{"2:6: beresp.ttl is not accessible from vcl_recv (variable-scope)"}},
+		"with invalid return action": {`sub vcl_recv {
+	return (deliver);
+}`, &ReturnActionRule{}, []string{"2:10: return(deliver) is not allowed in vcl_recv, want one of lookup, pass, pipe, error, restart (return-action)"}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			diagnostics := lint(t, tc.input, tc.rule)
+			if len(diagnostics) != len(tc.expected) {
+				t.Fatalf("diagnostics length wrong, got:%v, want:%v", diagnostics, tc.expected)
+			}
+
+			for i, d := range diagnostics {
+				if d.String() != tc.expected[i] {
+					t.Fatalf("diagnostic[%d] wrong, got:%s, want:%s", i, d, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLint_Ignore(t *testing.T) {
+	testCases := map[string]struct {
+		input         string
+		expectedCount int
+	}{
+		"without ignore": {`acl purge_ip {}
+table redirects {}`, 2},
+		"with ignore on previous line": {`# vcllint:ignore unused
+acl purge_ip {}
+table redirects {}`, 1},
+		"with ignore by double slash": {`// vcllint:ignore unused
+acl purge_ip {}`, 0},
+		"with ignore all rules": {`# vcllint:ignore
+acl purge_ip {}`, 0},
+		"with ignore other rule": {`# vcllint:ignore empty-if, duplicate-set
+acl purge_ip {}`, 1},
+		"with similar comment": {`# vcllint:ignored unused
+acl purge_ip {}`, 1},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			diagnostics := lint(t, tc.input, DefaultRules()...)
+			if len(diagnostics) != tc.expectedCount {
+				t.Fatalf("diagnostics length wrong, got:%v, want:%d", diagnostics, tc.expectedCount)
+			}
+		})
+	}
+}
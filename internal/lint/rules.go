@@ -0,0 +1,304 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/check"
+	"github.com/KeisukeYamashita/go-vcl/internal/regex"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/types"
+)
+
+// DefaultRules returns all rules of the linter
+func DefaultRules() []Rule {
+	return []Rule{
+		&UnusedRule{},
+		&ReadOnlyRule{},
+		&RegexRule{},
+		&PCRERegexRule{},
+		&EmptyIfRule{},
+		&DuplicateSetRule{},
+		&MissingReturnRule{},
+		&ScopeRule{},
+		&ReturnActionRule{},
+	}
+}
+
+// UnusedRule reports acls, backends, tables and subroutines which are declared but never referenced
+type UnusedRule struct{}
+
+// ID returns the rule ID
+func (*UnusedRule) ID() string { return "unused" }
+
+// Check runs the rule
+func (*UnusedRule) Check(pass *Pass) {
+	var firstBackend bool
+	for _, sym := range pass.Symbols.Symbols {
+		switch sym.Kind {
+		case resolve.Backend:
+			// The first backend is used as the default backend
+			if !firstBackend {
+				firstBackend = true
+				continue
+			}
+		case resolve.Subroutine:
+			if resolve.IsBuiltinSubroutine(sym.Name) {
+				continue
+			}
+		case resolve.ACL, resolve.Table:
+		default:
+			continue
+		}
+
+		if len(sym.References) == 0 {
+			pass.Reportf(sym.Pos, "%s %s is declared but not used", sym.Kind, sym.Name)
+		}
+	}
+}
+
+// ReadOnlyRule reports `set` and `unset` on read-only variables like client.ip
+type ReadOnlyRule struct{}
+
+// ID returns the rule ID
+func (*ReadOnlyRule) ID() string { return "readonly-variable" }
+
+// Check runs the rule
+func (*ReadOnlyRule) Check(pass *Pass) {
+	ast.Inspect(pass.Program, func(node ast.Node) bool {
+		var name *ast.Identifier
+		switch v := node.(type) {
+		case *ast.SetStatement:
+			name = v.Name
+		case *ast.UnsetStatement:
+			name = v.Name
+		default:
+			return true
+		}
+
+		if v, ok := types.LookupVariable(name.Value); ok && v.ReadOnly {
+			pass.Reportf(name.Token.Pos, "%s is read-only", name.Value)
+		}
+
+		return true
+	})
+}
+
+// RegexRule reports regular expressions which cannot be compiled
+type RegexRule struct{}
+
+// ID returns the rule ID
+func (*RegexRule) ID() string { return "invalid-regex" }
+
+// Check runs the rule
+func (*RegexRule) Check(pass *Pass) {
+	for _, err := range regex.Check(pass.Program) {
+		if e := err.(*regex.Error); !e.Warning {
+			pass.Reportf(e.Pos, "%s", e.Msg)
+		}
+	}
+}
+
+// PCRERegexRule reports regular expressions with PCRE-only constructs such as lookaheads which cannot be verified
+type PCRERegexRule struct{}
+
+// ID returns the rule ID
+func (*PCRERegexRule) ID() string { return "pcre-regex" }
+
+// Check runs the rule
+func (*PCRERegexRule) Check(pass *Pass) {
+	for _, err := range regex.Check(pass.Program) {
+		if e := err.(*regex.Error); e.Warning {
+			pass.Reportf(e.Pos, "%s", e.Msg)
+		}
+	}
+}
+
+// EmptyIfRule reports `if` and `else` with empty bodies
+type EmptyIfRule struct{}
+
+// ID returns the rule ID
+func (*EmptyIfRule) ID() string { return "empty-if" }
+
+// Check runs the rule
+func (*EmptyIfRule) Check(pass *Pass) {
+	ast.Inspect(pass.Program, func(node ast.Node) bool {
+		expr, ok := node.(*ast.IfExpression)
+		if !ok {
+			return true
+		}
+
+		if expr.Consequence != nil && isEmptyBlock(expr.Consequence) {
+			pass.Reportf(expr.Token.Pos, "empty if body")
+		}
+
+		if expr.Alternative != nil && isEmptyBlock(expr.Alternative) {
+			pass.Reportf(expr.Alternative.Token.Pos, "empty else body")
+		}
+
+		return true
+	})
+}
+
+// isEmptyBlock reports whether the block has no statements except comments
+func isEmptyBlock(block *ast.BlockStatement) bool {
+	for _, stmt := range block.Statements {
+		if _, ok := stmt.(*ast.CommentStatement); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DuplicateSetRule reports headers which are set twice in the same block without being read between them
+type DuplicateSetRule struct{}
+
+// ID returns the rule ID
+func (*DuplicateSetRule) ID() string { return "duplicate-set" }
+
+// Check runs the rule
+func (*DuplicateSetRule) Check(pass *Pass) {
+	ast.Inspect(pass.Program, func(node ast.Node) bool {
+		block, ok := node.(*ast.BlockStatement)
+		if !ok {
+			return true
+		}
+
+		sets := map[string]*ast.SetStatement{}
+		for _, stmt := range block.Statements {
+			// the called subroutine can read any header
+			if callsSubroutine(stmt) {
+				sets = map[string]*ast.SetStatement{}
+			}
+
+			for _, read := range readVariables(stmt) {
+				delete(sets, strings.ToLower(read))
+			}
+
+			set, ok := stmt.(*ast.SetStatement)
+			if !ok || !strings.Contains(set.Name.Value, ".http.") {
+				continue
+			}
+
+			key := strings.ToLower(set.Name.Value)
+			if prev, ok := sets[key]; ok {
+				pass.Reportf(set.Name.Token.Pos, "%s is set twice, previous set at %s", set.Name.Value, prev.Name.Token.Pos)
+			}
+
+			sets[key] = set
+		}
+
+		return true
+	})
+}
+
+// readVariables returns the variables which are read in the statement
+func readVariables(stmt ast.Statement) []string {
+	vars := []string{}
+	ast.InspectReads(stmt, func(node ast.Node) bool {
+		if v, ok := node.(*ast.Identifier); ok {
+			vars = append(vars, v.Value)
+		}
+
+		return true
+	})
+
+	return vars
+}
+
+// callsSubroutine reports whether the statement contains a `call` statement
+func callsSubroutine(stmt ast.Statement) bool {
+	var found bool
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		if _, ok := node.(*ast.CallStatement); ok {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// MissingReturnRule reports vcl_recv which does not end with `return`
+type MissingReturnRule struct{}
+
+// ID returns the rule ID
+func (*MissingReturnRule) ID() string { return "missing-return" }
+
+// Check runs the rule
+func (*MissingReturnRule) Check(pass *Pass) {
+	sym := pass.Symbols.Lookup(resolve.Subroutine, "vcl_recv")
+	if sym == nil {
+		return
+	}
+
+	// vcl_recv can be declared multiple times and they are concatenated
+	decl := sym.Decls[len(sym.Decls)-1]
+	if decl.Blocks == nil {
+		return
+	}
+
+	if !terminates(lastStatement(decl.Blocks)) {
+		pass.Reportf(decl.Token.Pos, "vcl_recv does not end with return")
+	}
+}
+
+// terminates reports whether the statement always returns.
+// It is a `return` or an if/else whose branches all end with terminating statements.
+func terminates(stmt ast.Statement) bool {
+	switch v := stmt.(type) {
+	case *ast.ReturnStatement:
+		return true
+	case *ast.ExpressionStatement:
+		expr, ok := v.Expression.(*ast.IfExpression)
+		if !ok || expr.Consequence == nil || expr.Alternative == nil {
+			return false
+		}
+
+		return terminates(lastStatement(expr.Consequence)) && terminates(lastStatement(expr.Alternative))
+	}
+
+	return false
+}
+
+// lastStatement returns the last statement of the block except comments
+func lastStatement(block *ast.BlockStatement) ast.Statement {
+	var last ast.Statement
+	for _, stmt := range block.Statements {
+		if _, ok := stmt.(*ast.CommentStatement); !ok {
+			last = stmt
+		}
+	}
+
+	return last
+}
+
+// ScopeRule reports variables which are not accessible from the subroutine in the dialect such as beresp.ttl in vcl_recv
+type ScopeRule struct{}
+
+// ID returns the rule ID
+func (*ScopeRule) ID() string { return "variable-scope" }
+
+// Check runs the rule
+func (*ScopeRule) Check(pass *Pass) {
+	for _, err := range check.Scopes(pass.Symbols, pass.Dialect) {
+		e := err.(*check.Error)
+		pass.Reportf(e.Pos, "%s", e.Msg)
+	}
+}
+
+// ReturnActionRule reports `return` actions which are not allowed in the subroutine in the dialect such as return(deliver) in vcl_recv
+type ReturnActionRule struct{}
+
+// ID returns the rule ID
+func (*ReturnActionRule) ID() string { return "return-action" }
+
+// Check runs the rule
+func (*ReturnActionRule) Check(pass *Pass) {
+	for _, err := range check.Returns(pass.Symbols, pass.Dialect) {
+		e := err.(*check.Error)
+		pass.Reportf(e.Pos, "%s", e.Msg)
+	}
+}
@@ -0,0 +1,272 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/callgraph"
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/lint"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+	"github.com/KeisukeYamashita/go-vcl/internal/types"
+)
+
+// document is an opened VCL file with the result of the analysis
+type document struct {
+	uri     string
+	version int
+	text    string
+
+	program     *ast.Program
+	symbols     *resolve.SymbolTable
+	diagnostics []Diagnostic
+
+	// lines are the byte offsets of the beginning of the lines
+	lines []int
+}
+
+// newDocument parses the text and runs the checks of the dialect
+func newDocument(uri string, version int, text string, d *dialect.Dialect) *document {
+	doc := &document{
+		uri:         uri,
+		version:     version,
+		text:        text,
+		diagnostics: []Diagnostic{},
+		lines:       []int{0},
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			doc.lines = append(doc.lines, i+1)
+		}
+	}
+
+	p := parser.NewParser(lexer.NewLexer(text))
+	doc.program = p.ParseProgram()
+	doc.report("syntax", SeverityError, p.Errors())
+
+	symbols, errs := resolve.Resolve(doc.program)
+	doc.symbols = symbols
+	doc.report("resolve", SeverityError, errs)
+
+	_, errs = callgraph.New(symbols)
+	doc.report("callgraph", SeverityError, errs)
+
+	doc.report("types", SeverityError, types.Check(doc.program))
+
+	linter := lint.NewLinter(lintRules()...)
+	linter.Dialect = d
+	for _, diag := range linter.Lint(doc.program) {
+		doc.diagnostics = append(doc.diagnostics, Diagnostic{
+			Range:    doc.wordRange(diag.Pos),
+			Severity: SeverityWarning,
+			Code:     diag.RuleID,
+			Source:   "vcllint",
+			Message:  diag.Message,
+		})
+	}
+
+	return doc
+}
+
+// lintRules are the default rules except the ones which the type check already reports
+func lintRules() []lint.Rule {
+	rules := []lint.Rule{}
+	for _, rule := range lint.DefaultRules() {
+		if _, ok := rule.(*lint.ReadOnlyRule); ok {
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// report adds the errors with the position to the diagnostics
+func (doc *document) report(source string, severity DiagnosticSeverity, errs []error) {
+	for _, err := range errs {
+		var pos token.Position
+		var msg string
+		sev := severity
+		switch e := err.(type) {
+		case *parser.Error:
+			pos, msg = e.Pos, e.Msg
+		case *resolve.Error:
+			pos, msg = e.Pos, e.Msg
+		case *callgraph.Error:
+			pos, msg = e.Pos, e.Msg
+		case *types.Error:
+			pos, msg = e.Pos, e.Msg
+			if e.Soft {
+				sev = SeverityInformation
+			}
+		default:
+			msg = err.Error()
+		}
+
+		doc.diagnostics = append(doc.diagnostics, Diagnostic{
+			Range:    doc.wordRange(pos),
+			Severity: sev,
+			Source:   source,
+			Message:  msg,
+		})
+	}
+}
+
+// isWordChar reports whether the byte is a part of the identifiers such as req.http.X-Forwarded-For
+func isWordChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '.' || c == '-'
+}
+
+// wordAt returns the byte offsets of the identifier which contains the offset
+func (doc *document) wordAt(offset int) (start, end int) {
+	start, end = offset, offset
+	for start > 0 && isWordChar(doc.text[start-1]) {
+		start--
+	}
+
+	for end < len(doc.text) && isWordChar(doc.text[end]) {
+		end++
+	}
+
+	return start, end
+}
+
+// wordRange returns the range of the identifier at the position.
+// It falls back to a character, or the beginning of the document if the position is unknown.
+func (doc *document) wordRange(pos token.Position) Range {
+	if !pos.IsValid() || pos.Offset > len(doc.text) {
+		return Range{}
+	}
+
+	start, end := pos.Offset, pos.Offset
+	for end < len(doc.text) && isWordChar(doc.text[end]) {
+		end++
+	}
+
+	if end == start && end < len(doc.text) {
+		_, size := utf8.DecodeRuneInString(doc.text[end:])
+		end += size
+	}
+
+	return doc.rangeOf(start, end)
+}
+
+// rangeOf returns the range of the byte offsets
+func (doc *document) rangeOf(start, end int) Range {
+	return Range{
+		Start: doc.position(start),
+		End:   doc.position(end),
+	}
+}
+
+// position converts the byte offset to the position in UTF-16 code units
+func (doc *document) position(offset int) Position {
+	if offset > len(doc.text) {
+		offset = len(doc.text)
+	}
+
+	line := sort.Search(len(doc.lines), func(i int) bool {
+		return doc.lines[i] > offset
+	}) - 1
+
+	character := 0
+	for _, r := range doc.text[doc.lines[line]:offset] {
+		character += utf16Len(r)
+	}
+
+	return Position{Line: line, Character: character}
+}
+
+// offset converts the position in UTF-16 code units to the byte offset
+func (doc *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+
+	if pos.Line >= len(doc.lines) {
+		return len(doc.text)
+	}
+
+	offset := doc.lines[pos.Line]
+	character := 0
+	for i, r := range doc.text[offset:] {
+		if character >= pos.Character || r == '\n' {
+			return offset + i
+		}
+
+		character += utf16Len(r)
+	}
+
+	return len(doc.text)
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
+
+// nameOffset returns the byte offset of the name of the declaration such as "F_origin" in `backend F_origin {`
+func (doc *document) nameOffset(decl *ast.BlockExpression) int {
+	start := decl.Token.Pos.Offset + len(decl.Token.Literal)
+	if start > len(doc.text) || len(decl.Labels) == 0 {
+		return decl.Token.Pos.Offset
+	}
+
+	i := strings.Index(doc.text[start:], decl.Labels[0])
+	if i < 0 {
+		return decl.Token.Pos.Offset
+	}
+
+	return start + i
+}
+
+// nameRange returns the range of the name of the declaration
+func (doc *document) nameRange(decl *ast.BlockExpression) Range {
+	offset := doc.nameOffset(decl)
+	return doc.rangeOf(offset, offset+len(decl.Labels[0]))
+}
+
+// declRange returns the range of the whole declaration
+func (doc *document) declRange(decl *ast.BlockExpression) Range {
+	end := doc.nameOffset(decl) + len(decl.Labels[0])
+	if decl.Blocks != nil && decl.Blocks.Rbrace.IsValid() {
+		end = decl.Blocks.Rbrace.Offset + 1
+	}
+
+	return doc.rangeOf(decl.Token.Pos.Offset, end)
+}
+
+// referenceRange returns the range of the identifier which refers to the symbol
+func (doc *document) referenceRange(ref *resolve.Reference) Range {
+	return doc.rangeOf(ref.Pos.Offset, ref.Pos.Offset+len(ref.Name))
+}
+
+// symbolAt returns the symbol which is declared or referred at the offset
+func (doc *document) symbolAt(offset int) *resolve.Symbol {
+	for _, ref := range doc.symbols.References {
+		if ref.Symbol != nil && ref.Pos.IsValid() && ref.Pos.Offset <= offset && offset <= ref.Pos.Offset+len(ref.Name) {
+			return ref.Symbol
+		}
+	}
+
+	for _, sym := range doc.symbols.Symbols {
+		for _, decl := range sym.Decls {
+			start := doc.nameOffset(decl)
+			if start <= offset && offset <= start+len(sym.Name) {
+				return sym
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/printer"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/types"
+)
+
+// symbolKinds maps the kind of the declarations to the kind in the outline
+var symbolKinds = map[resolve.Kind]SymbolKind{
+	resolve.Backend:     SymbolKindStruct,
+	resolve.Director:    SymbolKindStruct,
+	resolve.Probe:       SymbolKindStruct,
+	resolve.ACL:         SymbolKindArray,
+	resolve.Table:       SymbolKindObject,
+	resolve.Subroutine:  SymbolKindFunction,
+	resolve.Penaltybox:  SymbolKindVariable,
+	resolve.Ratecounter: SymbolKindVariable,
+}
+
+// definition returns the declarations of the symbol at the position.
+// Built-in subroutines like vcl_recv can have multiple declarations.
+func (doc *document) definition(pos Position) []Location {
+	sym := doc.symbolAt(doc.offset(pos))
+	if sym == nil {
+		return nil
+	}
+
+	locations := []Location{}
+	for _, decl := range sym.Decls {
+		locations = append(locations, Location{URI: doc.uri, Range: doc.nameRange(decl)})
+	}
+
+	return locations
+}
+
+// references returns the references to the symbol at the position
+func (doc *document) references(pos Position, includeDeclaration bool) []Location {
+	sym := doc.symbolAt(doc.offset(pos))
+	if sym == nil {
+		return nil
+	}
+
+	locations := []Location{}
+	if includeDeclaration {
+		for _, decl := range sym.Decls {
+			locations = append(locations, Location{URI: doc.uri, Range: doc.nameRange(decl)})
+		}
+	}
+
+	for _, ref := range sym.References {
+		locations = append(locations, Location{URI: doc.uri, Range: doc.referenceRange(ref)})
+	}
+
+	return locations
+}
+
+// hover describes the declaration, the built-in variable or the function at the position
+func (doc *document) hover(pos Position) *Hover {
+	offset := doc.offset(pos)
+	start, end := doc.wordAt(offset)
+	if start == end {
+		return nil
+	}
+
+	word := doc.text[start:end]
+	var value string
+	if sym := doc.symbolAt(offset); sym != nil {
+		value = codeBlock(fmt.Sprintf("%s %s", sym.Kind, sym.Name))
+	} else if v, ok := types.LookupVariable(word); ok {
+		value = codeBlock(fmt.Sprintf("%s %s", v.Type, word)) + "\n" + variableDoc(v)
+	} else if f, ok := types.LookupFunction(word); ok {
+		value = codeBlock(signature(f)) + "\n" + f.Doc
+	} else {
+		return nil
+	}
+
+	r := doc.rangeOf(start, end)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value},
+		Range:    &r,
+	}
+}
+
+func codeBlock(s string) string {
+	return "```vcl\n" + s + "\n```"
+}
+
+func variableDoc(v *types.Variable) string {
+	if v.ReadOnly {
+		return v.Doc + " (read-only)"
+	}
+
+	return v.Doc
+}
+
+// signature returns the signature of the function such as `STRING regsub(STRING, STRING, STRING)`.
+// The optional parameters are enclosed by the brackets.
+func signature(f *types.Function) string {
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = p.String()
+	}
+
+	if f.Optional > 0 {
+		n := len(params) - f.Optional
+		params[n] = "[" + params[n]
+		params[len(params)-1] += "]"
+	}
+
+	return fmt.Sprintf("%s %s(%s)", f.Return, f.Name, strings.Join(params, ", "))
+}
+
+// documentSymbols returns the outline of the declarations with the fields such as .host
+func (doc *document) documentSymbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, sym := range doc.symbols.Symbols {
+		for _, decl := range sym.Decls {
+			symbols = append(symbols, DocumentSymbol{
+				Name:           sym.Name,
+				Detail:         sym.Kind.String(),
+				Kind:           symbolKinds[sym.Kind],
+				Range:          doc.declRange(decl),
+				SelectionRange: doc.nameRange(decl),
+				Children:       doc.fields(decl),
+			})
+		}
+	}
+
+	return symbols
+}
+
+// fields returns the assignments in the declaration such as .host of the backend
+func (doc *document) fields(decl *ast.BlockExpression) []DocumentSymbol {
+	if decl.Blocks == nil {
+		return nil
+	}
+
+	fields := []DocumentSymbol{}
+	for _, stmt := range decl.Blocks.Statements {
+		assign, ok := stmt.(*ast.AssignStatement)
+		if !ok || !assign.Name.Token.Pos.IsValid() {
+			continue
+		}
+
+		r := doc.wordRange(assign.Name.Token.Pos)
+		fields = append(fields, DocumentSymbol{
+			Name:           assign.Name.Value,
+			Kind:           SymbolKindField,
+			Range:          r,
+			SelectionRange: r,
+		})
+	}
+
+	return fields
+}
+
+// completion returns the built-in variables, the functions and the declarations which start with the word before the position
+func (doc *document) completion(pos Position) *CompletionList {
+	offset := doc.offset(pos)
+	start := offset
+	for start > 0 && isWordChar(doc.text[start-1]) {
+		start--
+	}
+
+	prefix := doc.text[start:offset]
+	r := doc.rangeOf(start, offset)
+	list := &CompletionList{Items: []CompletionItem{}}
+	add := func(item CompletionItem) {
+		if !strings.HasPrefix(item.Label, prefix) {
+			return
+		}
+
+		// the edit replaces the whole prefix because the names contain dots which end the words of the editors
+		item.TextEdit = &TextEdit{Range: r, NewText: item.Label}
+		list.Items = append(list.Items, item)
+	}
+
+	for _, sym := range doc.symbols.Symbols {
+		add(CompletionItem{
+			Label:  sym.Name,
+			Kind:   CompletionKindReference,
+			Detail: sym.Kind.String(),
+		})
+	}
+
+	for _, v := range types.Variables() {
+		add(CompletionItem{
+			Label:         v.Name,
+			Kind:          CompletionKindVariable,
+			Detail:        v.Type.String(),
+			Documentation: variableDoc(v),
+		})
+	}
+
+	for _, f := range types.Functions() {
+		add(CompletionItem{
+			Label:         f.Name,
+			Kind:          CompletionKindFunction,
+			Detail:        signature(f),
+			Documentation: f.Doc,
+		})
+	}
+
+	return list
+}
+
+// formatting replaces the whole document with the output of the printer.
+// It fails if the document has syntax errors or cannot be formatted without changing the program.
+func (doc *document) formatting() ([]TextEdit, *responseError) {
+	formatted, err := printer.Format([]byte(doc.text))
+	if err != nil {
+		return nil, errorf(codeRequestFailed, "cannot format: %s", err)
+	}
+
+	if string(formatted) == doc.text {
+		return []TextEdit{}, nil
+	}
+
+	return []TextEdit{{
+		Range:   doc.rangeOf(0, len(doc.text)),
+		NewText: string(formatted),
+	}}, nil
+}
@@ -0,0 +1,276 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+)
+
+// errExitWithoutShutdown is returned by Serve when the client exits before the shutdown request
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Server is a language server of VCL which speaks JSON-RPC over a stream such as stdio
+type Server struct {
+	dialect   *dialect.Dialect
+	documents map[string]*document
+	shutdown  bool
+
+	mu  sync.Mutex // guards out
+	out io.Writer
+}
+
+// NewServer returns a server for the default dialect.
+// The dialect can be changed by the "dialect" in the initialization options.
+func NewServer() *Server {
+	return &Server{
+		dialect:   dialect.Default,
+		documents: map[string]*document{},
+	}
+}
+
+// Serve reads the messages from r and writes the responses and the notifications to w.
+// It returns nil when the client exits after the shutdown or closes the input.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	in := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(in)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			var rerr *responseError
+			if errors.As(err, &rerr) {
+				// the message is framed correctly, so the next one can still be read
+				if err := s.write(&message{ID: nullID(), Error: rerr}); err != nil {
+					return err
+				}
+				continue
+			}
+
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+		if msg.ID == nil {
+			// notifications do not have the response
+			continue
+		}
+
+		resp := &message{ID: msg.ID}
+		if rerr != nil {
+			resp.Error = rerr
+		} else if resp.Result, err = json.Marshal(result); err != nil {
+			resp.Error = errorf(codeRequestFailed, "%s", err)
+		}
+
+		if err := s.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func nullID() *json.RawMessage {
+	id := json.RawMessage("null")
+	return &id
+}
+
+func (s *Server) write(msg *message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeMessage(s.out, msg)
+}
+
+// notify sends the notification to the client
+func (s *Server) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return s.write(&message{Method: method, Params: raw})
+}
+
+// handle runs the method and returns the result of the request
+func (s *Server) handle(msg *message) (interface{}, *responseError) {
+	if s.shutdown && msg.Method != "shutdown" {
+		return nil, errorf(codeInvalidRequest, "server is shut down")
+	}
+
+	switch msg.Method {
+	case "initialize":
+		params := &InitializeParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		return s.initialize(params)
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		params := &DidOpenTextDocumentParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		item := params.TextDocument
+		return nil, s.update(item.URI, item.Version, item.Text)
+	case "textDocument/didChange":
+		params := &DidChangeTextDocumentParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// the sync is full, so the last change has the whole text
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Version, text)
+	case "textDocument/didClose":
+		params := &DidCloseTextDocumentParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		// the diagnostics of the closed document are cleared
+		if err := s.publish(&document{uri: params.TextDocument.URI, diagnostics: []Diagnostic{}}); err != nil {
+			return nil, errorf(codeRequestFailed, "%s", err)
+		}
+		return nil, nil
+	case "textDocument/definition":
+		params := &TextDocumentPositionParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		return s.withDocument(params.TextDocument.URI, func(doc *document) interface{} {
+			return doc.definition(params.Position)
+		})
+	case "textDocument/references":
+		params := &ReferenceParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		return s.withDocument(params.TextDocument.URI, func(doc *document) interface{} {
+			return doc.references(params.Position, params.Context.IncludeDeclaration)
+		})
+	case "textDocument/hover":
+		params := &TextDocumentPositionParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		return s.withDocument(params.TextDocument.URI, func(doc *document) interface{} {
+			return doc.hover(params.Position)
+		})
+	case "textDocument/documentSymbol":
+		params := &DocumentSymbolParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		return s.withDocument(params.TextDocument.URI, func(doc *document) interface{} {
+			return doc.documentSymbols()
+		})
+	case "textDocument/completion":
+		params := &TextDocumentPositionParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		return s.withDocument(params.TextDocument.URI, func(doc *document) interface{} {
+			return doc.completion(params.Position)
+		})
+	case "textDocument/formatting":
+		params := &DocumentFormattingParams{}
+		if err := decodeParams(msg.Params, params); err != nil {
+			return nil, err
+		}
+		doc, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			return nil, errorf(codeInvalidParams, "unknown document %s", params.TextDocument.URI)
+		}
+		return doc.formatting()
+	}
+
+	if msg.ID == nil {
+		// unknown notifications such as $/cancelRequest are ignored
+		return nil, nil
+	}
+
+	return nil, errorf(codeMethodNotFound, "method %q is not supported", msg.Method)
+}
+
+func decodeParams(raw json.RawMessage, v interface{}) *responseError {
+	if len(raw) == 0 {
+		return errorf(codeInvalidParams, "missing params")
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errorf(codeInvalidParams, "invalid params: %s", err)
+	}
+
+	return nil
+}
+
+func (s *Server) initialize(params *InitializeParams) (interface{}, *responseError) {
+	if opts := params.InitializationOptions; opts != nil && opts.Dialect != "" {
+		d, ok := dialect.Lookup(opts.Dialect)
+		if !ok {
+			return nil, errorf(codeInvalidParams, "unknown dialect %q", opts.Dialect)
+		}
+		s.dialect = d
+	}
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           textDocumentSyncFull,
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			HoverProvider:              true,
+			DocumentSymbolProvider:     true,
+			CompletionProvider:         &CompletionOptions{TriggerCharacters: []string{"."}},
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "vcl-lsp"},
+	}, nil
+}
+
+// update analyzes the new text of the document and publishes the diagnostics
+func (s *Server) update(uri string, version int, text string) *responseError {
+	doc := newDocument(uri, version, text, s.dialect)
+	s.documents[uri] = doc
+
+	if err := s.publish(doc); err != nil {
+		return errorf(codeRequestFailed, "%s", err)
+	}
+
+	return nil
+}
+
+func (s *Server) publish(doc *document) error {
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     doc.version,
+		Diagnostics: doc.diagnostics,
+	})
+}
+
+func (s *Server) withDocument(uri string, fn func(*document) interface{}) (interface{}, *responseError) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil, errorf(codeInvalidParams, "unknown document %s", uri)
+	}
+
+	return fn(doc), nil
+}
@@ -0,0 +1,557 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+)
+
+// client is an in-process JSON-RPC client of the server.
+// The messages from the server are read in background because the pipes are not buffered.
+type client struct {
+	w        io.WriteCloser
+	messages chan *message
+	id       int
+	done     chan error
+	queued   []*message // notifications read while waiting for the response
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{
+		w:        clientOut,
+		messages: make(chan *message, 100),
+		done:     make(chan error, 1),
+	}
+
+	go func() {
+		defer close(c.messages)
+
+		r := bufio.NewReader(clientIn)
+		for {
+			msg, err := readMessage(r)
+			if err != nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+
+	go func() {
+		err := NewServer().Serve(serverIn, serverOut)
+		serverOut.Close()
+		c.done <- err
+	}()
+
+	t.Cleanup(func() {
+		clientOut.Close()
+		clientIn.Close()
+	})
+
+	return c
+}
+
+func (c *client) send(t *testing.T, msg *message) {
+	t.Helper()
+
+	if err := writeMessage(c.w, msg); err != nil {
+		t.Fatalf("writeMessage() failed, err:%v", err)
+	}
+}
+
+// call sends the request and decodes the result of the response into result
+func (c *client) call(t *testing.T, method string, params, result interface{}) *responseError {
+	t.Helper()
+
+	c.id++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(t, c.id))))
+	c.send(t, &message{ID: &id, Method: method, Params: mustMarshal(t, params)})
+
+	for {
+		msg := c.receive(t)
+		if msg.ID == nil {
+			c.queued = append(c.queued, msg)
+			continue
+		}
+
+		if string(*msg.ID) != string(id) {
+			t.Fatalf("got:%s, want:%s", *msg.ID, id)
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				t.Fatalf("json.Unmarshal() failed, err:%v", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func (c *client) notify(t *testing.T, method string, params interface{}) {
+	t.Helper()
+	c.send(t, &message{Method: method, Params: mustMarshal(t, params)})
+}
+
+// diagnostics waits for the next diagnostics published by the server
+func (c *client) diagnostics(t *testing.T) *PublishDiagnosticsParams {
+	t.Helper()
+
+	for {
+		var msg *message
+		if len(c.queued) > 0 {
+			msg, c.queued = c.queued[0], c.queued[1:]
+		} else {
+			msg = c.receive(t)
+		}
+
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+
+		params := &PublishDiagnosticsParams{}
+		if err := json.Unmarshal(msg.Params, params); err != nil {
+			t.Fatalf("json.Unmarshal() failed, err:%v", err)
+		}
+
+		return params
+	}
+}
+
+// receive returns the next message from the server
+func (c *client) receive(t *testing.T) *message {
+	t.Helper()
+
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			t.Fatalf("server is closed")
+		}
+		return msg
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timeout to receive the message")
+	}
+
+	return nil
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed, err:%v", err)
+	}
+
+	return b
+}
+
+const testURI = "file:///default.vcl"
+
+const testSource = `backend F_origin {
+	.host = "example.com";
+}
+
+acl internal {
+	"10.0.0.0"/8;
+}
+
+table redirects {
+	"/old": "/new",
+}
+
+sub normalize {
+	unset req.http.Cookie;
+}
+
+sub vcl_recv {
+	if (client.ip ~ internal) {
+		set req.backend = F_origin;
+	}
+	set req.url = table.lookup(redirects, req.url, req.url);
+	call normalize;
+	return (lookup);
+}
+`
+
+// open initializes the server and opens the document
+func open(t *testing.T, text string) *client {
+	t.Helper()
+
+	c := newClient(t)
+	result := &InitializeResult{}
+	if err := c.call(t, "initialize", &InitializeParams{}, result); err != nil {
+		t.Fatalf("initialize failed, err:%v", err)
+	}
+
+	if !result.Capabilities.DefinitionProvider || !result.Capabilities.DocumentFormattingProvider {
+		t.Fatalf("capabilities are not advertised, got:%+v", result.Capabilities)
+	}
+
+	c.notify(t, "initialized", struct{}{})
+	c.notify(t, "textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "vcl", Version: 1, Text: text},
+	})
+
+	return c
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	c := open(t, testSource)
+
+	if got := c.diagnostics(t); len(got.Diagnostics) != 0 {
+		t.Fatalf("got:%v, want no diagnostics", got.Diagnostics)
+	}
+
+	c.notify(t, "textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument: VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: `sub vcl_recv {
+	set req.http.A = ;
+	call missing;
+	set client.ip = "127.0.0.1";
+	set beresp.ttl = 10s;
+	return (lookup);
+}
+`}},
+	})
+
+	got := c.diagnostics(t)
+	if got.Version != 2 {
+		t.Fatalf("got:%v, want:%v", got.Version, 2)
+	}
+
+	testCases := map[string]struct {
+		source   string
+		severity DiagnosticSeverity
+		line     int
+	}{
+		"syntax":  {"syntax", SeverityError, 1},
+		"resolve": {"resolve", SeverityError, 2},
+		"types":   {"types", SeverityError, 3},
+		"dialect": {"vcllint", SeverityWarning, 4},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			for _, d := range got.Diagnostics {
+				if d.Source == tc.source && d.Severity == tc.severity && d.Range.Start.Line == tc.line {
+					return
+				}
+			}
+
+			t.Fatalf("got:%+v, want %s diagnostic at line %d", got.Diagnostics, tc.source, tc.line)
+		})
+	}
+
+	// closing the document clears the diagnostics
+	c.notify(t, "textDocument/didClose", &DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}})
+	if got := c.diagnostics(t); len(got.Diagnostics) != 0 {
+		t.Fatalf("got:%v, want no diagnostics", got.Diagnostics)
+	}
+}
+
+func TestServer_Definition(t *testing.T) {
+	c := open(t, testSource)
+
+	testCases := map[string]struct {
+		position Position
+		expected []Location
+	}{
+		"backend": {Position{Line: 18, Character: 22}, []Location{{testURI, Range{Position{0, 8}, Position{0, 16}}}}},
+		"acl":     {Position{Line: 17, Character: 20}, []Location{{testURI, Range{Position{4, 4}, Position{4, 12}}}}},
+		"table":   {Position{Line: 20, Character: 30}, []Location{{testURI, Range{Position{8, 6}, Position{8, 15}}}}},
+		"sub":     {Position{Line: 21, Character: 7}, []Location{{testURI, Range{Position{12, 4}, Position{12, 13}}}}},
+		"builtin": {Position{Line: 18, Character: 8}, nil},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got []Location
+			params := &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: testURI}, Position: tc.position}
+			if err := c.call(t, "textDocument/definition", params, &got); err != nil {
+				t.Fatalf("definition failed, err:%v", err)
+			}
+
+			if len(got) != len(tc.expected) {
+				t.Fatalf("got:%v, want:%v", got, tc.expected)
+			}
+
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("got:%v, want:%v", got[i], tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_References(t *testing.T) {
+	c := open(t, testSource)
+
+	testCases := map[string]struct {
+		position           Position
+		includeDeclaration bool
+		expected           []Range
+	}{
+		"from declaration": {Position{Line: 12, Character: 6}, false, []Range{{Position{21, 6}, Position{21, 15}}}},
+		"with declaration": {Position{Line: 21, Character: 10}, true, []Range{{Position{12, 4}, Position{12, 13}}, {Position{21, 6}, Position{21, 15}}}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got []Location
+			params := &ReferenceParams{
+				TextDocumentPositionParams: TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: testURI}, Position: tc.position},
+				Context:                    ReferenceContext{IncludeDeclaration: tc.includeDeclaration},
+			}
+			if err := c.call(t, "textDocument/references", params, &got); err != nil {
+				t.Fatalf("references failed, err:%v", err)
+			}
+
+			if len(got) != len(tc.expected) {
+				t.Fatalf("got:%v, want:%v", got, tc.expected)
+			}
+
+			for i := range got {
+				if got[i].Range != tc.expected[i] {
+					t.Fatalf("got:%v, want:%v", got[i].Range, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_Hover(t *testing.T) {
+	c := open(t, testSource)
+
+	testCases := map[string]struct {
+		position Position
+		expected string
+	}{
+		"variable": {Position{Line: 20, Character: 8}, "STRING req.url"},
+		"header":   {Position{Line: 13, Character: 12}, "STRING req.http.Cookie"},
+		"function": {Position{Line: 20, Character: 18}, "table.lookup("},
+		"symbol":   {Position{Line: 18, Character: 22}, "backend F_origin"},
+		"nothing":  {Position{Line: 2, Character: 0}, ""},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var got *Hover
+			params := &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: testURI}, Position: tc.position}
+			if err := c.call(t, "textDocument/hover", params, &got); err != nil {
+				t.Fatalf("hover failed, err:%v", err)
+			}
+
+			if tc.expected == "" {
+				if got != nil {
+					t.Fatalf("got:%v, want:nil", got)
+				}
+				return
+			}
+
+			if got == nil || !strings.Contains(got.Contents.Value, tc.expected) {
+				t.Fatalf("got:%v, want:%v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestServer_DocumentSymbol(t *testing.T) {
+	c := open(t, testSource)
+
+	var got []DocumentSymbol
+	params := &DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: testURI}}
+	if err := c.call(t, "textDocument/documentSymbol", params, &got); err != nil {
+		t.Fatalf("documentSymbol failed, err:%v", err)
+	}
+
+	expected := []struct {
+		name  string
+		kind  SymbolKind
+		start int
+		end   int
+	}{
+		{"F_origin", SymbolKindStruct, 0, 2},
+		{"internal", SymbolKindArray, 4, 6},
+		{"redirects", SymbolKindObject, 8, 10},
+		{"normalize", SymbolKindFunction, 12, 14},
+		{"vcl_recv", SymbolKindFunction, 16, 23},
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("got:%v, want:%v", got, expected)
+	}
+
+	for i, want := range expected {
+		if got[i].Name != want.name || got[i].Kind != want.kind || got[i].Range.Start.Line != want.start || got[i].Range.End.Line != want.end {
+			t.Fatalf("got:%+v, want:%+v", got[i], want)
+		}
+	}
+
+	if fields := got[0].Children; len(fields) != 1 || fields[0].Name != ".host" {
+		t.Fatalf("got:%v, want:%v", fields, ".host")
+	}
+}
+
+func TestServer_Completion(t *testing.T) {
+	c := open(t, testSource+"sub vcl_deliver {\n\tset resp.http.X = req.ur\n}\n")
+
+	list := &CompletionList{}
+	params := &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: testURI}, Position: Position{Line: 25, Character: 25}}
+	if err := c.call(t, "textDocument/completion", params, list); err != nil {
+		t.Fatalf("completion failed, err:%v", err)
+	}
+
+	labels := map[string]CompletionItem{}
+	for _, item := range list.Items {
+		if !strings.HasPrefix(item.Label, "req.ur") {
+			t.Fatalf("got:%v, want the items start with req.ur", item.Label)
+		}
+		labels[item.Label] = item
+	}
+
+	item, ok := labels["req.url"]
+	if !ok {
+		t.Fatalf("got:%v, want:%v", list.Items, "req.url")
+	}
+
+	if item.Kind != CompletionKindVariable || item.Detail != "STRING" {
+		t.Fatalf("got:%+v, want the variable of STRING", item)
+	}
+
+	want := Range{Position{25, 19}, Position{25, 25}}
+	if item.TextEdit == nil || item.TextEdit.Range != want {
+		t.Fatalf("got:%v, want:%v", item.TextEdit, want)
+	}
+
+	// declarations and functions are also completed
+	for _, prefix := range []string{"F_or", "regsub"} {
+		c.notify(t, "textDocument/didChange", &DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: testURI, Version: 2},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: testSource + prefix}},
+		})
+
+		list := &CompletionList{}
+		params := &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: testURI}, Position: Position{Line: 23, Character: len(prefix)}}
+		if err := c.call(t, "textDocument/completion", params, list); err != nil {
+			t.Fatalf("completion failed, err:%v", err)
+		}
+
+		if len(list.Items) == 0 {
+			t.Fatalf("got no items, want items for %s", prefix)
+		}
+	}
+}
+
+func TestServer_Formatting(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+		fails    bool
+	}{
+		"formatted": {
+			input:    "sub vcl_recv {\n  set req.url = \"/\";\n}\n",
+			expected: "sub vcl_recv {\n\tset req.url = \"/\";\n}\n",
+		},
+		"syntax error": {
+			input: "sub vcl_recv {\n  set = ;\n}\n",
+			fails: true,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			c := open(t, tc.input)
+
+			var got []TextEdit
+			params := &DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: testURI}}
+			err := c.call(t, "textDocument/formatting", params, &got)
+			if tc.fails {
+				if err == nil || err.Code != codeRequestFailed {
+					t.Fatalf("got:%v, want the request failure", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("formatting failed, err:%v", err)
+			}
+
+			if len(got) != 1 || got[0].NewText != tc.expected {
+				t.Fatalf("got:%v, want:%q", got, tc.expected)
+			}
+
+			want := Range{Position{0, 0}, Position{3, 0}}
+			if got[0].Range != want {
+				t.Fatalf("got:%v, want:%v", got[0].Range, want)
+			}
+		})
+	}
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	c := newClient(t)
+
+	if err := c.call(t, "initialize", &InitializeParams{InitializationOptions: &InitializationOptions{Dialect: "unknown"}}, nil); err == nil || err.Code != codeInvalidParams {
+		t.Fatalf("got:%v, want the invalid params", err)
+	}
+
+	if err := c.call(t, "textDocument/unknown", struct{}{}, nil); err == nil || err.Code != codeMethodNotFound {
+		t.Fatalf("got:%v, want the method not found", err)
+	}
+
+	if err := c.call(t, "textDocument/hover", &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: "file:///unknown.vcl"}}, nil); err == nil || err.Code != codeInvalidParams {
+		t.Fatalf("got:%v, want the invalid params", err)
+	}
+
+	if err := c.call(t, "shutdown", nil, nil); err != nil {
+		t.Fatalf("shutdown failed, err:%v", err)
+	}
+
+	c.notify(t, "exit", nil)
+	if err := <-c.done; err != nil {
+		t.Fatalf("Serve() failed, err:%v", err)
+	}
+}
+
+func TestDocument_Position(t *testing.T) {
+	doc := newDocument(testURI, 1, "# é😀\nsub vcl_recv {}\n", dialect.Default)
+
+	testCases := map[string]struct {
+		offset   int
+		expected Position
+	}{
+		"beginning":        {0, Position{0, 0}},
+		"after two bytes":  {4, Position{0, 3}},
+		"after surrogates": {8, Position{0, 5}},
+		"next line":        {9, Position{1, 0}},
+		"end":              {len(doc.text), Position{2, 0}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got := doc.position(tc.offset)
+			if got != tc.expected {
+				t.Fatalf("got:%v, want:%v", got, tc.expected)
+			}
+
+			if offset := doc.offset(got); offset != tc.offset {
+				t.Fatalf("got:%v, want:%v", offset, tc.offset)
+			}
+		})
+	}
+}
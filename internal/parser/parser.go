@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/KeisukeYamashita/go-vcl/internal/ast"
 	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
@@ -10,16 +11,29 @@ import (
 )
 
 var precedences = map[token.Type]int{
-	token.EQUAL: EQUALS,
-	token.MATCH: EQUALS,
-	token.PLUS:  SUM,
-	token.AND:   EQUALS,
-	token.OR:    EQUALS,
+	token.EQUAL:    EQUALS,
+	token.NOTEQUAL: EQUALS,
+	token.MATCH:    EQUALS,
+	token.NOTMATCH: EQUALS,
+	token.LT:       LESSGREATER,
+	token.GT:       LESSGREATER,
+	token.LE:       LESSGREATER,
+	token.GE:       LESSGREATER,
+	token.PLUS:     SUM,
+	token.MINUS:    SUM,
+	token.ASTERISK: PRODUCT,
+	token.SLASH:    PRODUCT,
+	token.PERCENT:  PRODUCT,
+	token.AND:      LOGICALAND,
+	token.OR:       LOGICALOR,
+	token.LPAREN:   CALL,
 }
 
 const (
 	_ int = iota
 	LOWEST
+	LOGICALOR
+	LOGICALAND
 	EQUALS
 	LESSGREATER
 	SUM
@@ -33,6 +47,17 @@ type (
 	infixParseFn  func(ast.Expression) ast.Expression
 )
 
+// Error is a syntax error with the position
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
 // Parser is a struct that contains a lexer and parse spec
 type Parser struct {
 	l         *lexer.Lexer
@@ -42,6 +67,12 @@ type Parser struct {
 	errors        []error
 	prefixParseFn map[token.Type]prefixParseFn
 	infixParseFn  map[token.Type]infixParseFn
+
+	// pending is the number of the errors which are not recovered yet
+	pending int
+
+	// atBlockEnd is true when the recovery stops at the closing brace of the enclosing block
+	atBlockEnd bool
 }
 
 // NewParser returns a parser by lexer
@@ -62,11 +93,13 @@ func (p *Parser) init() {
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.PERCENTAGE, p.parsePercentageLiteral)
+	p.registerPrefix(token.RTIME, p.parseRTimeLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.CIDR, p.parseCIDRLiteral)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
+	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.SUBROUTINE, p.parseBlockExpression)
@@ -75,10 +108,27 @@ func (p *Parser) init() {
 	p.registerPrefix(token.DIRECTOR, p.parseBlockExpression)
 	p.registerPrefix(token.LBRACE, p.parseObjectExpression)
 	p.registerPrefix(token.TABLE, p.parseBlockExpression)
+	p.registerPrefix(token.PENALTYBOX, p.parseBlockExpression)
+	p.registerPrefix(token.RATECOUNTER, p.parseBlockExpression)
+	p.registerPrefix(token.PROBE, p.parseBlockExpression)
 
 	p.infixParseFn = make(map[token.Type]infixParseFn)
 	p.registerInfix(token.MATCH, p.parseInfixExpression)
+	p.registerInfix(token.NOTMATCH, p.parseInfixExpression)
+	p.registerInfix(token.EQUAL, p.parseInfixExpression)
+	p.registerInfix(token.NOTEQUAL, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.LT, p.parseInfixExpression)
+	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LE, p.parseInfixExpression)
+	p.registerInfix(token.GE, p.parseInfixExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
+	p.registerInfix(token.MINUS, p.parseInfixExpression)
+	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.SLASH, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.LPAREN, p.parseCallExpression)
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
@@ -95,8 +145,8 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		p.errors = append(p.errors, err)
-		return nil
+		p.errorf(p.curToken.Pos, "invalid integer %s", p.curToken.Literal)
+		return &ast.BadExpression{Token: p.curToken}
 	}
 
 	lit.Value = value
@@ -121,6 +171,15 @@ func (p *Parser) parsePercentageLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseRTimeLiteral() ast.Expression {
+	lit := &ast.RTimeLiteral{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
+
+	return lit
+}
+
 func (p *Parser) parseCIDRLiteral() ast.Expression {
 	lit := &ast.CIDRLiteral{
 		Token: p.curToken,
@@ -160,6 +219,40 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expr
 }
 
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	expr := &ast.CallExpression{
+		Token:    p.curToken,
+		Function: function,
+	}
+
+	expr.Arguments = p.parseCallArguments()
+	return expr
+}
+
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return args
+	}
+
+	p.nextToken()
+	args = append(args, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		args = append(args, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return args
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expr := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -181,9 +274,19 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	}
 
 	p.nextToken()
+	pending := p.pending
+	start := p.curToken
 	expr.Condition = p.parseExpression(LOWEST)
 
-	if !p.expectPeek(token.RPAREN) {
+	if p.pending > pending {
+		// the error is recovered in the condition so that the body is still parsed
+		if !p.skipToRParen() {
+			return nil
+		}
+
+		p.pending = pending
+		expr.Condition = &ast.BadExpression{Token: start}
+	} else if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
@@ -196,6 +299,12 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			expr.Alternative = p.parseElseIfStatement()
+			return expr
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -206,13 +315,34 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expr
 }
 
+// parseElseIfStatement parses `else if (...) { ... }` as an alternative block
+// which only contains the nested if expression.
+func (p *Parser) parseElseIfStatement() *ast.BlockStatement {
+	tok := p.curToken
+
+	expr := p.parseIfExpression()
+	if expr == nil {
+		return nil
+	}
+
+	return &ast.BlockStatement{
+		Token: tok,
+		Statements: []ast.Statement{
+			&ast.ExpressionStatement{
+				Token:      tok,
+				Expression: expr,
+			},
+		},
+	}
+}
+
 func (p *Parser) parseBlockExpression() ast.Expression {
 	expr := &ast.BlockExpression{
 		Token: p.curToken,
 	}
 
 	labels := []string{}
-	for !p.peekTokenIs(token.LBRACE) && !p.peekTokenIs(token.SEMICOLON) {
+	for !p.peekTokenIs(token.LBRACE) && !p.peekTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.EOF) {
 		p.nextToken()
 		labels = append(labels, p.curToken.Literal)
 	}
@@ -223,12 +353,10 @@ func (p *Parser) parseBlockExpression() ast.Expression {
 		return expr
 	}
 
-	if !p.peekTokenIs(token.LBRACE) {
+	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
-	p.nextToken()
-
 	expr.Blocks = p.parseBlockStatement()
 	return expr
 }
@@ -245,9 +373,24 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
+
+		if p.atBlockEnd {
+			// the recovery stops at the closing brace of this block
+			p.atBlockEnd = false
+			break
+		}
 		p.nextToken()
 	}
 
+	block.Rbrace = p.curToken.Pos
+	if p.curTokenIs(token.EOF) {
+		// the block is kept so that the rest of the statements are available
+		p.errors = append(p.errors, &Error{
+			Pos: p.curToken.Pos,
+			Msg: fmt.Sprintf("expected %s, got %s", token.RBRACE, token.EOF),
+		})
+	}
+
 	return block
 }
 
@@ -294,14 +437,104 @@ func (p *Parser) ParseProgram() *ast.Program {
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
+
+		// the stray closing brace is skipped
+		p.atBlockEnd = false
 		p.nextToken()
 	}
 	return program
 }
 
+// parseStatement parses the statement and recovers from the syntax errors in it.
+// The tokens are skipped to the end of the statement and a BadStatement is returned instead.
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken
+	pending := p.pending
+
+	stmt := p.parseStatementByToken()
+	if p.pending == pending {
+		return stmt
+	}
+
+	p.synchronize()
+	p.pending = pending
+	return &ast.BadStatement{
+		Token: start,
+		End:   p.curToken.Pos,
+	}
+}
+
+// synchronize skips the tokens to the end of the broken statement which is the next `;` or the matching `}`.
+// The closing brace of the enclosing block is not skipped and p.atBlockEnd is set if the current token is it.
+func (p *Parser) synchronize() {
+	depth := 0
+	for {
+		switch p.curToken.Type {
+		case token.EOF:
+			return
+		case token.SEMICOLON:
+			if depth == 0 {
+				return
+			}
+		case token.ILLEGAL:
+			// the unterminated string ends at the end of the line
+			if depth == 0 && isUnterminatedString(p.curToken) {
+				return
+			}
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth == 0 {
+				p.atBlockEnd = true
+				return
+			}
+
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+
+		if depth == 0 && (p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF)) {
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
+// skipToRParen skips the tokens to the closing parenthesis of the grouped expression.
+// It returns false if the statement ends before it.
+func (p *Parser) skipToRParen() bool {
+	depth := 0
+	for {
+		switch p.curToken.Type {
+		case token.EOF, token.SEMICOLON, token.LBRACE, token.RBRACE:
+			return false
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			if depth == 0 {
+				return true
+			}
+			depth--
+		}
+
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseStatementByToken() ast.Statement {
 	switch p.curToken.Type {
 	case token.IDENT:
+		// set, unset, probe, etc. are keywords only when they are followed by a name
+		if p.peekTokenIs(token.IDENT) {
+			if tokenType, ok := token.LookupStatementKeyword(p.curToken.Literal); ok {
+				p.curToken.Type = tokenType
+				return p.parseStatementByToken()
+			}
+		}
+
 		switch p.peekToken.Type {
 		case token.ASSIGN:
 			return p.parseAssignStatement()
@@ -318,6 +551,10 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.CALL:
 		return p.parseCallStatement()
+	case token.SET:
+		return p.parseSetStatement()
+	case token.UNSET:
+		return p.parseUnsetStatement()
 	case token.STRING:
 		switch p.peekToken.Type {
 		case token.COLON:
@@ -340,6 +577,62 @@ func (p *Parser) parseAssignStatement() ast.Statement {
 	}
 
 	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// setOperators are the operators of the set statement
+var setOperators = map[token.Type]bool{
+	token.ASSIGN:       true,
+	token.ADDASSIGN:    true,
+	token.SUBASSIGN:    true,
+	token.MULASSIGN:    true,
+	token.DIVASSIGN:    true,
+	token.MODASSIGN:    true,
+	token.BITORASSIGN:  true,
+	token.BITANDASSIGN: true,
+	token.BITXORASSIGN: true,
+	token.LSHIFTASSIGN: true,
+	token.RSHIFTASSIGN: true,
+	token.ANDASSIGN:    true,
+	token.ORASSIGN:     true,
+}
+
+func (p *Parser) parseSetStatement() ast.Statement {
+	stmt := &ast.SetStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
+
+	// ror= and rol= are lexed as an identifier followed by =
+	if p.peekTokenIs(token.IDENT) && (p.peekToken.Literal == "ror" || p.peekToken.Literal == "rol") {
+		p.nextToken()
+		operator := p.curToken.Literal
+		if !p.expectPeek(token.ASSIGN) {
+			return nil
+		}
+		stmt.Operator = operator + p.curToken.Literal
+	} else if setOperators[p.peekToken.Type] {
+		p.nextToken()
+		stmt.Operator = p.curToken.Literal
+	} else {
 		p.peekError(token.ASSIGN)
 		return nil
 	}
@@ -354,6 +647,27 @@ func (p *Parser) parseAssignStatement() ast.Statement {
 	return stmt
 }
 
+func (p *Parser) parseUnsetStatement() ast.Statement {
+	stmt := &ast.UnsetStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseAssignFieldStatement() ast.Statement {
 	stmt := &ast.AssignFieldStatement{
 		Token: p.curToken,
@@ -365,7 +679,6 @@ func (p *Parser) parseAssignFieldStatement() ast.Statement {
 	}
 
 	if !p.expectPeek(token.COLON) {
-		p.peekError(token.COLON)
 		return nil
 	}
 
@@ -384,8 +697,13 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 		Token: p.curToken,
 	}
 
+	// `return;` returns from the subroutine without the action
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		return stmt
+	}
+
 	if !p.expectPeek(token.LPAREN) {
-		p.peekError(token.ASSIGN)
 		return nil
 	}
 
@@ -394,7 +712,6 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(token.RPAREN) {
-		p.peekError(token.ASSIGN)
 		return nil
 	}
 
@@ -419,23 +736,19 @@ func (p *Parser) parseMultiCommentStatement() ast.Statement {
 		Token: p.curToken,
 	}
 
-	var value string
 	p.nextToken()
 	for !p.curTokenIs(token.RMULTICOMMENTLINE) {
 		if p.curTokenIs(token.EOF) {
+			p.errorf(stmt.Token.Pos, "comment is not terminated")
 			return nil
 		}
 
-		if value == "" {
-			value += p.curToken.Literal
-		} else {
-			value += " " + p.curToken.Literal
-		}
-
 		p.nextToken()
 	}
 
-	stmt.Value = value
+	// the text is taken from the input so that the line breaks and the characters which are not tokens are kept
+	start := stmt.Token.Pos.Offset + len(stmt.Token.Literal)
+	stmt.Value = strings.TrimSpace(p.l.Input()[start:p.curToken.Pos.Offset])
 	return stmt
 }
 
@@ -471,7 +784,12 @@ func (p *Parser) parseExpressionStatement() ast.Statement {
 func (p *Parser) parseExpression(precedentce int) ast.Expression {
 	prefix := p.prefixParseFn[p.curToken.Type]
 	if prefix == nil {
-		return nil
+		if isUnterminatedString(p.curToken) {
+			p.errorf(p.curToken.Pos, "string is not terminated")
+		} else {
+			p.errorf(p.curToken.Pos, "unexpected %s", describe(p.curToken))
+		}
+		return &ast.BadExpression{Token: p.curToken}
 	}
 
 	leftExp := prefix()
@@ -487,9 +805,37 @@ func (p *Parser) parseExpression(precedentce int) ast.Expression {
 	return leftExp
 }
 
+// errorf records the syntax error which is recovered by parseStatement
+func (p *Parser) errorf(pos token.Position, format string, args ...interface{}) {
+	p.errors = append(p.errors, &Error{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	})
+	p.pending++
+}
+
 func (p *Parser) peekError(t token.Type) {
-	err := fmt.Errorf("expected to be token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, err)
+	p.errorf(p.peekToken.Pos, "expected %s, got %s", t, describe(p.peekToken))
+}
+
+// describe returns the token for the error messages
+func describe(tok token.Token) string {
+	switch tok.Type {
+	case token.EOF:
+		return string(token.EOF)
+	case token.ILLEGAL, "":
+		if isUnterminatedString(tok) {
+			return fmt.Sprintf("unterminated string %s", tok.Literal)
+		}
+		return fmt.Sprintf("illegal character %q", tok.Literal)
+	}
+
+	return fmt.Sprintf("%q", tok.Literal)
+}
+
+// isUnterminatedString reports whether the token is a string without the closing quote
+func isUnterminatedString(tok token.Token) bool {
+	return tok.Type == token.ILLEGAL && strings.HasPrefix(tok.Literal, "\"")
 }
 
 func (p *Parser) expectPeek(t token.Type) bool {
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/KeisukeYamashita/go-vcl/internal/ast"
@@ -142,10 +143,12 @@ func TestCommentStatement(t *testing.T) {
 		input           string
 		expectedComment string
 	}{
-		"with comment line by hash":         {`# keke`, "keke"},
-		"with comment line by double slash": {"// keke", "keke"},
-		"with single comment by multi line": {"/* keke */", "keke"},
-		"with long comment by multi line":   {"/* keke is happy */", "keke is happy"},
+		"with comment line by hash":          {`# keke`, "keke"},
+		"with comment line by double slash":  {"// keke", "keke"},
+		"with single comment by multi line":  {"/* keke */", "keke"},
+		"with long comment by multi line":    {"/* keke is happy */", "keke is happy"},
+		"with hash without space":            {`#keke`, "keke"},
+		"with multi line comment as written": {"/* keke's\n   happy */", "keke's\n   happy"},
 	}
 
 	for n, tc := range testCases {
@@ -431,6 +434,32 @@ func TestPercentageLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestRTimeLiteralExpression(t *testing.T) {
+	input := "10s;"
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements length is not expected, got:%d, want:%d", len(program.Statements), 1)
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement, got:%T", program.Statements[0])
+	}
+
+	lit, ok := stmt.Expression.(*ast.RTimeLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.RTimeLiteral, got:%T", stmt.Expression)
+	}
+
+	if lit.Value != "10s" {
+		t.Fatalf("lit.Value wrong, got:%s, want:%s", lit.Value, "10s")
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	input := `true;
 false;`
@@ -470,6 +499,14 @@ func TestParsingInfixExpressions(t *testing.T) {
 		rightValue int64
 	}{
 		{"5 ~ 5", 5, "~", 5},
+		{"5 < 5", 5, "<", 5},
+		{"5 > 5", 5, ">", 5},
+		{"5 <= 5", 5, "<=", 5},
+		{"5 >= 5", 5, ">=", 5},
+		{"5 - 5", 5, "-", 5},
+		{"5 * 5", 5, "*", 5},
+		{"5 / 5", 5, "/", 5},
+		{"5 % 5", 5, "%", 5},
 	}
 
 	for n, tc := range testCases {
@@ -512,6 +549,7 @@ func TestParsingPrefixExpressions(t *testing.T) {
 		integerValue int64
 	}{
 		{"!5", "!", 5},
+		{"-5", "-", 5},
 	}
 
 	for n, tc := range testCases {
@@ -585,6 +623,70 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
+func TestArithmeticExpression(t *testing.T) {
+	testCases := map[string]struct {
+		input                 string
+		expectedOperator      string
+		expectedLeftOperator  string
+		expectedRightOperator string
+	}{
+		"with product inside sum":      {`1 + 2 * 3`, "+", "", "*"},
+		"with sum inside relational":   {`a - 1 >= b % 2`, ">=", "-", "%"},
+		"with relational inside and":   {`a < 1 && b > 2`, "&&", "<", ">"},
+		"with unary minus inside sum":  {`-a - -1`, "-", "-", "-"},
+		"with left associative sum":    {`a - b - c`, "-", "-", ""},
+		"with left associative divide": {`a / b * c`, "*", "/", ""},
+	}
+
+	operator := func(expr ast.Expression) string {
+		switch v := expr.(type) {
+		case *ast.InfixExpression:
+			return v.Operator
+		case *ast.PrefixExpression:
+			return v.Operator
+		}
+		return ""
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			if len(program.Statements) != 1 {
+				t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+			}
+
+			stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+			if !ok {
+				t.Fatalf("program.Statement[0] is not ast.ExpressionStatement, got:%T", program.Statements[0])
+			}
+
+			expr, ok := stmt.Expression.(*ast.InfixExpression)
+			if !ok {
+				t.Fatalf("stmt.Expression is not ast.InfixExpression, got:%T", stmt.Expression)
+			}
+
+			if expr.Operator != tc.expectedOperator {
+				t.Fatalf("root operator wrong, got:%s, want:%s", expr.Operator, tc.expectedOperator)
+			}
+
+			if got := operator(expr.Left); got != tc.expectedLeftOperator {
+				t.Fatalf("left operator wrong, got:%s, want:%s", got, tc.expectedLeftOperator)
+			}
+
+			if got := operator(expr.Right); got != tc.expectedRightOperator {
+				t.Fatalf("right operator wrong, got:%s, want:%s", got, tc.expectedRightOperator)
+			}
+		})
+	}
+}
+
 func TestIfStatement(t *testing.T) {
 	testCases := []struct {
 		input string
@@ -810,3 +912,458 @@ func testStringLiteral(t *testing.T, expr ast.Expression, value string) bool {
 
 	return true
 }
+
+func TestSetStatement(t *testing.T) {
+	testCases := map[string]struct {
+		input            string
+		expectedName     string
+		expectedOperator string
+		expectedValue    string
+	}{
+		"with string":              {`set req.http.X-Foo = "bar";`, "req.http.X-Foo", "=", "bar"},
+		"with identifier":          {`set req.backend = F_backend1;`, "req.backend", "=", "F_backend1"},
+		"with addition":            {`set req.http.X-Foo += "bar";`, "req.http.X-Foo", "+=", "bar"},
+		"with subtraction":         {`set var.count -= 1;`, "var.count", "-=", "1"},
+		"with multiplication":      {`set var.count *= 2;`, "var.count", "*=", "2"},
+		"with division":            {`set var.count /= 2;`, "var.count", "/=", "2"},
+		"with modulo":              {`set var.count %= 2;`, "var.count", "%=", "2"},
+		"with bitwise or":          {`set var.count |= 1;`, "var.count", "|=", "1"},
+		"with bitwise and":         {`set var.count &= 1;`, "var.count", "&=", "1"},
+		"with bitwise xor":         {`set var.count ^= 1;`, "var.count", "^=", "1"},
+		"with left shift":          {`set var.count <<= 1;`, "var.count", "<<=", "1"},
+		"with right shift":         {`set var.count >>= 1;`, "var.count", ">>=", "1"},
+		"with right rotation":      {`set var.count ror= 1;`, "var.count", "ror=", "1"},
+		"with left rotation":       {`set var.count rol= 1;`, "var.count", "rol=", "1"},
+		"with logical and":         {`set var.enabled &&= false;`, "var.enabled", "&&=", "false"},
+		"with logical or":          {`set var.enabled ||= true;`, "var.enabled", "||=", "true"},
+		"with keyword as variable": {`set probe = 1;`, "probe", "=", "1"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			if len(program.Statements) != 1 {
+				t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+			}
+
+			stmt, ok := program.Statements[0].(*ast.SetStatement)
+			if !ok {
+				t.Fatalf("program.Statement[0] is not ast.SetStatement, got:%T", program.Statements[0])
+			}
+
+			if stmt.Name.Value != tc.expectedName {
+				t.Fatalf("stmt.Name wrong, got:%s, want:%s", stmt.Name.Value, tc.expectedName)
+			}
+
+			if stmt.Operator != tc.expectedOperator {
+				t.Fatalf("stmt.Operator wrong, got:%s, want:%s", stmt.Operator, tc.expectedOperator)
+			}
+
+			if stmt.Value.TokenLiteral() != tc.expectedValue {
+				t.Fatalf("stmt.Value wrong, got:%s, want:%s", stmt.Value.TokenLiteral(), tc.expectedValue)
+			}
+		})
+	}
+}
+
+func TestUnsetStatement(t *testing.T) {
+	l := lexer.NewLexer(`unset req.http.Cookie;`)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+	}
+
+	stmt, ok := program.Statements[0].(*ast.UnsetStatement)
+	if !ok {
+		t.Fatalf("program.Statement[0] is not ast.UnsetStatement, got:%T", program.Statements[0])
+	}
+
+	if stmt.Name.Value != "req.http.Cookie" {
+		t.Fatalf("stmt.Name wrong, got:%s, want:%s", stmt.Name.Value, "req.http.Cookie")
+	}
+}
+
+func TestStatementKeywordAsIdentifier(t *testing.T) {
+	testCases := map[string]struct {
+		input        string
+		expectedType string
+	}{
+		"with set as assigned name":    {`set = 1;`, "*ast.AssignStatement"},
+		"with unset as assigned name":  {`unset = 1;`, "*ast.AssignStatement"},
+		"with probe as assigned name":  {`probe = "origin";`, "*ast.AssignStatement"},
+		"with probe declaration":       {`probe origin {}`, "*ast.ExpressionStatement"},
+		"with penaltybox declaration":  {`penaltybox banned {}`, "*ast.ExpressionStatement"},
+		"with ratecounter declaration": {`ratecounter requests {}`, "*ast.ExpressionStatement"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			if len(program.Statements) != 1 {
+				t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+			}
+
+			if got := fmt.Sprintf("%T", program.Statements[0]); got != tc.expectedType {
+				t.Fatalf("program.Statement[0] wrong type, got:%s, want:%s", got, tc.expectedType)
+			}
+		})
+	}
+}
+
+func TestCallExpression(t *testing.T) {
+	testCases := map[string]struct {
+		input             string
+		expectedFunction  string
+		expectedArguments int
+	}{
+		"with no argument":        {`now();`, "now", 0},
+		"with single argument":    {`std.tolower(req.url);`, "std.tolower", 1},
+		"with multiple arguments": {`table.lookup(my_table, req.url, "default");`, "table.lookup", 3},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			if len(program.Statements) != 1 {
+				t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+			}
+
+			stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+			if !ok {
+				t.Fatalf("program.Statement[0] is not ast.ExpressionStatement, got:%T", program.Statements[0])
+			}
+
+			expr, ok := stmt.Expression.(*ast.CallExpression)
+			if !ok {
+				t.Fatalf("stmt.Expression is not ast.CallExpression, got:%T", stmt.Expression)
+			}
+
+			if !testIdentifier(t, expr.Function, tc.expectedFunction) {
+				t.Fatalf("callExpression failed to test function identifier")
+			}
+
+			if len(expr.Arguments) != tc.expectedArguments {
+				t.Fatalf("callExpression arguments length wrong, got:%d, want:%d", len(expr.Arguments), tc.expectedArguments)
+			}
+		})
+	}
+}
+
+func TestLogicalExpression(t *testing.T) {
+	testCases := map[string]struct {
+		input            string
+		expectedOperator string
+	}{
+		"with and":             {`a == b && c ~ d`, "&&"},
+		"with or":              {`a != b || c !~ d`, "||"},
+		"with and inside or":   {`a && b || c`, "||"},
+		"with or inside right": {`a || b && c`, "||"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			if len(program.Statements) != 1 {
+				t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+			}
+
+			stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+			if !ok {
+				t.Fatalf("program.Statement[0] is not ast.ExpressionStatement, got:%T", program.Statements[0])
+			}
+
+			expr, ok := stmt.Expression.(*ast.InfixExpression)
+			if !ok {
+				t.Fatalf("stmt.Expression is not ast.InfixExpression, got:%T", stmt.Expression)
+			}
+
+			if expr.Operator != tc.expectedOperator {
+				t.Fatalf("root operator wrong, got:%s, want:%s", expr.Operator, tc.expectedOperator)
+			}
+		})
+	}
+}
+
+func TestElseIfStatement(t *testing.T) {
+	input := `if (x ~ y) {
+	x
+} else if (y ~ z) {
+	y
+} else {
+	z
+}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 1)
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statement[0] is not ast.ExpressionStatement, got:%T", program.Statements[0])
+	}
+
+	expr, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression, got:%T", stmt.Expression)
+	}
+
+	if expr.Alternative == nil || len(expr.Alternative.Statements) != 1 {
+		t.Fatalf("alternative should contain the else if expression, got:%#v", expr.Alternative)
+	}
+
+	nested, ok := expr.Alternative.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("alternative is not ast.IfExpression, got:%T", expr.Alternative.Statements[0])
+	}
+
+	if !testInfixExpression(t, nested.Condition, "y", "~", "z") {
+		t.Fatalf("else if failed to parse condition")
+	}
+
+	if nested.Alternative == nil || len(nested.Alternative.Statements) != 1 {
+		t.Fatalf("else block is not parsed, got:%#v", nested.Alternative)
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	input := `x = "foo;
+y = "bar";`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	errs := p.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("parser errors wrong length, got:%v, want:%d", errs, 1)
+	}
+
+	if want := "1:5: string is not terminated"; errs[0].Error() != want {
+		t.Fatalf("parser error wrong, got:%s, want:%s", errs[0], want)
+	}
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements wrong length got:%d, want:%d", len(program.Statements), 2)
+	}
+
+	stmt, ok := program.Statements[1].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("program.Statement[1] is not ast.AssignStatement, got:%T", program.Statements[1])
+	}
+
+	if !testStringLiteral(t, stmt.Value, "bar") {
+		t.Fatalf("the statement after the unterminated string is not parsed")
+	}
+}
+
+// statementTypes describes the statements and the statements of the blocks like "BlockExpression[SetStatement]"
+func statementTypes(stmts []ast.Statement) []string {
+	ret := []string{}
+	for _, stmt := range stmts {
+		desc := strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ast.")
+		if es, ok := stmt.(*ast.ExpressionStatement); ok {
+			desc = strings.TrimPrefix(fmt.Sprintf("%T", es.Expression), "*ast.")
+			switch expr := es.Expression.(type) {
+			case *ast.BlockExpression:
+				if expr.Blocks != nil {
+					desc += fmt.Sprintf("%v", statementTypes(expr.Blocks.Statements))
+				}
+			case *ast.IfExpression:
+				desc += fmt.Sprintf("(%s)", strings.TrimPrefix(fmt.Sprintf("%T", expr.Condition), "*ast."))
+				if expr.Consequence != nil {
+					desc += fmt.Sprintf("%v", statementTypes(expr.Consequence.Statements))
+				}
+			}
+		}
+
+		ret = append(ret, desc)
+	}
+
+	return ret
+}
+
+func TestParseProgram_ErrorRecovery(t *testing.T) {
+	testCases := map[string]struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements []string
+	}{
+		"with missing value": {`sub vcl_recv {
+	set req.url = ;
+	set req.http.X = "1";
+}`, []string{`2:16: unexpected ";"`}, []string{"BlockExpression[BadStatement SetStatement]"}},
+		"with missing operator": {`sub vcl_recv { set req.url "a" }
+sub vcl_deliver {}`, []string{`1:28: expected =, got "a"`}, []string{"BlockExpression[BadStatement]", "BlockExpression[]"}},
+		"with invalid condition": {`sub vcl_recv {
+	if (req.url ~ ) {
+		set req.http.X = "1";
+	}
+}`, []string{`2:16: unexpected ")"`}, []string{"BlockExpression[IfExpression(BadExpression)[SetStatement]]"}},
+		"with unclosed block": {`sub vcl_recv {
+	set req.url = "/";`, []string{"2:20: expected }, got EOF"}, []string{"BlockExpression[SetStatement]"}},
+		"with stray closing brace": {`}
+acl local {}`, []string{`1:1: unexpected "}"`}, []string{"BadStatement", "BlockExpression[]"}},
+		"with missing parenthesis": {`sub vcl_recv {
+	if (req.url ~ "a" {
+		set req.http.X = "1";
+	}
+	set req.http.Y = "2";
+}
+sub vcl_deliver {}`, []string{`2:20: expected ), got "{"`}, []string{"BlockExpression[BadStatement SetStatement]", "BlockExpression[]"}},
+		"with unclosed call": {`sub vcl_recv {
+	set req.http.X = regsub(req.url, "a";
+	set req.http.Y = "2";
+}`, []string{`2:38: expected ), got ";"`}, []string{"BlockExpression[BadStatement SetStatement]"}},
+		"with unterminated string": {`sub vcl_recv {
+	set req.http.X = "foo;
+	set req.http.Y = "2";
+}`, []string{"2:19: string is not terminated"}, []string{"BlockExpression[BadStatement SetStatement]"}},
+		"with illegal character": {`sub vcl_recv {
+	set req.http.X = "1" ^ "2";
+	return (lookup);
+}`, []string{`2:23: unexpected illegal character "^"`}, []string{"BlockExpression[SetStatement BadStatement ReturnStatement]"}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			errs := p.Errors()
+			if len(errs) != len(tc.expectedErrors) {
+				t.Fatalf("errors length wrong, got:%v, want:%v", errs, tc.expectedErrors)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.expectedErrors[i] {
+					t.Fatalf("error[%d] wrong, got:%s, want:%s", i, err, tc.expectedErrors[i])
+				}
+			}
+
+			if got := statementTypes(program.Statements); fmt.Sprint(got) != fmt.Sprint(tc.expectedStatements) {
+				t.Fatalf("statements wrong, got:%v, want:%v", got, tc.expectedStatements)
+			}
+		})
+	}
+}
+
+func FuzzParseProgram(f *testing.F) {
+	for _, seed := range []string{
+		`acl local { "localhost"; "192.168.0.0"/24; }`,
+		`backend F_origin { .host = "example.com"; .probe = { .url = "/"; } }`,
+		`director my_dir random { .quorum = 50%; { .backend = F_origin; .weight = 1; } }`,
+		`table redirects { "/old": "/new", }`,
+		`sub vcl_recv { if (req.url ~ "^/foo" && !req.http.X) { set req.http.X = regsub(req.url, "a", "b"); } else if (req.restarts == 0) { unset req.http.Y; } return (lookup); }`,
+		`sub vcl_fetch { set beresp.ttl = 10s; call helper; }`,
+		`sub vcl_recv {`,
+		`acl`,
+		`x = `,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		p := NewParser(lexer.NewLexer(input))
+		p.ParseProgram()
+	})
+}
+
+func TestReturnStatement_WithoutAction(t *testing.T) {
+	input := `sub my_helper {
+	if (req.url ~ "^/foo") {
+		return;
+	}
+	set req.http.X = "1";
+}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser has errors, errs:%v", p.Errors())
+	}
+
+	if got := statementTypes(program.Statements); fmt.Sprint(got) != "[BlockExpression[IfExpression(InfixExpression)[ReturnStatement] SetStatement]]" {
+		t.Fatalf("statements wrong, got:%v", got)
+	}
+
+	ifExpr := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.BlockExpression).Blocks.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	stmt := ifExpr.Consequence.Statements[0].(*ast.ReturnStatement)
+	if stmt.ReturnValue != nil {
+		t.Fatalf("stmt.ReturnValue should be nil, got:%#v", stmt.ReturnValue)
+	}
+}
+
+func TestParseProgram_Operators(t *testing.T) {
+	testCases := map[string]struct {
+		input              string
+		expectedStatements []string
+	}{
+		"with relational condition": {`sub vcl_fetch {
+	if (beresp.status >= 500) {
+		return (error);
+	}
+}`, []string{"BlockExpression[IfExpression(InfixExpression)[ReturnStatement]]"}},
+		"with all relational operators": {`sub vcl_fetch {
+	if (beresp.status < 200 || beresp.status > 299 || beresp.ttl <= 0s || obj.hits >= 1) {}
+}`, []string{"BlockExpression[IfExpression(InfixExpression)[]]"}},
+		"with negative relative time": {`sub vcl_fetch {
+	set beresp.ttl = -1s;
+}`, []string{"BlockExpression[SetStatement]"}},
+		"with arithmetic": {`sub vcl_fetch {
+	set beresp.ttl = beresp.ttl * 2 - 10s / 5;
+	set beresp.status = beresp.status % 100;
+}`, []string{"BlockExpression[SetStatement SetStatement]"}},
+		"with unary minus condition": {`sub vcl_recv {
+	if (-req.restarts < -1) {}
+}`, []string{"BlockExpression[IfExpression(InfixExpression)[]]"}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := NewParser(l)
+			program := p.ParseProgram()
+
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			if got := statementTypes(program.Statements); fmt.Sprint(got) != fmt.Sprint(tc.expectedStatements) {
+				t.Fatalf("statements wrong, got:%v, want:%v", got, tc.expectedStatements)
+			}
+		})
+	}
+}
@@ -0,0 +1,579 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// indent is the indentation of a nested block
+const indent = "\t"
+
+// precedences are the binding powers of the infix operators which decide the parentheses
+var precedences = map[string]int{
+	token.OR:       1,
+	token.AND:      2,
+	token.EQUAL:    3,
+	token.NOTEQUAL: 3,
+	token.MATCH:    3,
+	token.NOTMATCH: 3,
+	token.LT:       4,
+	token.GT:       4,
+	token.LE:       4,
+	token.GE:       4,
+	token.PLUS:     5,
+	token.MINUS:    5,
+	token.ASTERISK: 6,
+	token.SLASH:    6,
+	token.PERCENT:  6,
+}
+
+// prefixPrecedence is the binding power of `!` and unary `-`
+const prefixPrecedence = 7
+
+// errNilProgram is returned when the program to print is nil
+var errNilProgram = errors.New("cannot print nil program")
+
+// Fprint writes the program to w in the canonical format.
+// Statements are printed one per line with tabs for the indentation, and blank lines between the statements are kept.
+func Fprint(w io.Writer, program *ast.Program) error {
+	if program == nil {
+		return errNilProgram
+	}
+
+	p := &printer{}
+	p.statements(program.Statements)
+	if p.err != nil {
+		return p.err
+	}
+
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+// Print returns the program in the canonical format
+func Print(program *ast.Program) (string, error) {
+	var buf bytes.Buffer
+	if err := Fprint(&buf, program); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Format parses the source and returns it in the canonical format.
+// It fails if the source has syntax errors, or if the formatted source does not consist of the same tokens
+// as the original which happens for the constructs the parser does not keep such as redundant parentheses.
+func Format(src []byte) ([]byte, error) {
+	p := parser.NewParser(lexer.NewLexer(string(src)))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	var buf bytes.Buffer
+	if err := Fprint(&buf, program); err != nil {
+		return nil, err
+	}
+
+	if err := sameTokens(string(src), buf.String()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sameTokens reports an error at the first token which differs between the sources.
+// Parentheses are ignored because the printer only writes the ones which are necessary for the precedence,
+// and so is the trailing comma of the table entries which the printer always writes.
+func sameTokens(src, formatted string) error {
+	want := lexer.NewLexer(src)
+	got := lexer.NewLexer(formatted)
+	for {
+		w := nextToken(want)
+		g := nextToken(got)
+		if g.Type == token.COMMA && w.Type == token.RBRACE {
+			g = nextToken(got)
+		}
+
+		if w.Type != g.Type || w.Literal != g.Literal {
+			return fmt.Errorf("%s: cannot format %q without changing the program", w.Pos, w.Literal)
+		}
+
+		if w.Type == token.EOF {
+			return nil
+		}
+	}
+}
+
+func nextToken(l *lexer.Lexer) token.Token {
+	for {
+		tok := l.NextToken()
+		if tok.Type != token.LPAREN && tok.Type != token.RPAREN {
+			return tok
+		}
+	}
+}
+
+type printer struct {
+	buf   bytes.Buffer
+	depth int
+	err   error
+}
+
+func (p *printer) print(args ...string) {
+	for _, s := range args {
+		p.buf.WriteString(s)
+	}
+}
+
+// newline ends the line
+func (p *printer) newline() {
+	p.buf.WriteByte('\n')
+}
+
+func (p *printer) indent() {
+	p.print(strings.Repeat(indent, p.depth))
+}
+
+func (p *printer) errorf(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (p *printer) statements(stmts []ast.Statement) {
+	for i := 0; i < len(stmts); i++ {
+		stmt := stmts[i]
+		if i > 0 && blankLineBetween(stmts[i-1], stmt, p.depth) {
+			p.newline()
+		}
+
+		p.indent()
+
+		// statements on the same line such as `error 404 "Not Found";` are printed as written
+		for ; continued(stmts, i); i++ {
+			p.expression(stmts[i].(*ast.ExpressionStatement).Expression, 0)
+			p.print(" ")
+		}
+
+		p.statement(stmts[i])
+
+		// a comment after the statement stays on the same line
+		if i+1 < len(stmts) && trailingComment(stmts[i], stmts[i+1]) {
+			i++
+			p.print(" ")
+			p.statement(stmts[i])
+		}
+
+		p.newline()
+	}
+}
+
+// continued reports whether the statement is a part of the statement which continues on the same line.
+// It is the case of the actions such as `error` and `synthetic` which the parser reads as separate expressions.
+func continued(stmts []ast.Statement, i int) bool {
+	if i+1 >= len(stmts) {
+		return false
+	}
+
+	cur, ok := stmts[i].(*ast.ExpressionStatement)
+	if !ok || !isSimple(cur.Expression) {
+		return false
+	}
+
+	next, ok := stmts[i+1].(*ast.ExpressionStatement)
+	if !ok || !isSimple(next.Expression) {
+		return false
+	}
+
+	if !cur.Token.Pos.IsValid() || cur.Token.Pos.Line != next.Token.Pos.Line {
+		return false
+	}
+
+	// the chain starts with the action name such as `error`
+	for j := i; j >= 0; j-- {
+		es, ok := stmts[j].(*ast.ExpressionStatement)
+		if !ok || es.Token.Pos.Line != cur.Token.Pos.Line {
+			return false
+		}
+
+		if _, ok := es.Expression.(*ast.Identifier); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSimple reports whether the expression does not have a block
+func isSimple(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.BlockExpression, *ast.IfExpression:
+		return false
+	}
+
+	return true
+}
+
+// trailingComment reports whether the comment is written after the statement on the same line
+func trailingComment(stmt, next ast.Statement) bool {
+	comment, ok := next.(*ast.CommentStatement)
+	if !ok || !comment.Token.Pos.IsValid() {
+		return false
+	}
+
+	if _, ok := stmt.(*ast.CommentStatement); ok {
+		return false
+	}
+
+	return endLine(stmt) == comment.Token.Pos.Line
+}
+
+// blankLineBetween reports whether a blank line is printed between the statements.
+// Blank lines in the source are kept, and declarations at the top level are separated when the positions are unknown.
+func blankLineBetween(prev, next ast.Statement, depth int) bool {
+	start := startPos(next)
+	if start.IsValid() && endLine(prev) > 0 {
+		return start.Line > endLine(prev)+1
+	}
+
+	if depth > 0 {
+		return false
+	}
+
+	if _, ok := prev.(*ast.CommentStatement); ok {
+		return false
+	}
+
+	return isDeclaration(prev) || isDeclaration(next)
+}
+
+func isDeclaration(stmt ast.Statement) bool {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+
+	_, ok = es.Expression.(*ast.BlockExpression)
+	return ok
+}
+
+func startPos(stmt ast.Statement) token.Position {
+	switch v := stmt.(type) {
+	case *ast.AssignStatement:
+		return v.Name.Token.Pos
+	case *ast.AssignFieldStatement:
+		return v.Name.Token.Pos
+	case *ast.ExpressionStatement:
+		return v.Token.Pos
+	case *ast.SetStatement:
+		return v.Token.Pos
+	case *ast.UnsetStatement:
+		return v.Token.Pos
+	case *ast.ReturnStatement:
+		return v.Token.Pos
+	case *ast.CallStatement:
+		return v.Token.Pos
+	case *ast.CommentStatement:
+		return v.Token.Pos
+	case *ast.BadStatement:
+		return v.Token.Pos
+	}
+
+	return token.Position{}
+}
+
+// endLine returns the last line of the statement, 0 if unknown
+func endLine(stmt ast.Statement) int {
+	var block *ast.BlockStatement
+	switch v := stmt.(type) {
+	case *ast.ExpressionStatement:
+		block = lastBlock(v.Expression)
+	case *ast.AssignStatement:
+		block = lastBlock(v.Value)
+	case *ast.AssignFieldStatement:
+		block = lastBlock(v.Value)
+	case *ast.CommentStatement:
+		if v.Token.Pos.IsValid() {
+			return v.Token.Pos.Line + strings.Count(v.Value, "\n")
+		}
+	}
+
+	if block != nil {
+		return block.Rbrace.Line
+	}
+
+	return startPos(stmt).Line
+}
+
+// lastBlock returns the block which ends the expression
+func lastBlock(expr ast.Expression) *ast.BlockStatement {
+	switch v := expr.(type) {
+	case *ast.BlockExpression:
+		return v.Blocks
+	case *ast.IfExpression:
+		if v.Alternative == nil {
+			return v.Consequence
+		}
+
+		if alt, ok := elseIf(v.Alternative); ok {
+			return lastBlock(alt)
+		}
+
+		return v.Alternative
+	}
+
+	return nil
+}
+
+// elseIf returns the if expression of `else if`
+func elseIf(block *ast.BlockStatement) (*ast.IfExpression, bool) {
+	if block.Token.Type != token.IF || len(block.Statements) != 1 {
+		return nil, false
+	}
+
+	es, ok := block.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+
+	expr, ok := es.Expression.(*ast.IfExpression)
+	return expr, ok
+}
+
+func (p *printer) statement(stmt ast.Statement) {
+	switch v := stmt.(type) {
+	case *ast.AssignStatement:
+		p.print(v.Name.Value, " = ")
+		p.expression(v.Value, 0)
+		if isSimple(v.Value) {
+			p.print(";")
+		}
+	case *ast.AssignFieldStatement:
+		p.print(`"`, v.Name.Value, `": `)
+		p.expression(v.Value, 0)
+		p.print(",")
+	case *ast.SetStatement:
+		operator := v.Operator
+		if operator == "" {
+			operator = "="
+		}
+
+		p.print("set ", v.Name.Value, " ", operator, " ")
+		p.expression(v.Value, 0)
+		p.print(";")
+	case *ast.UnsetStatement:
+		p.print("unset ", v.Name.Value, ";")
+	case *ast.ReturnStatement:
+		if v.ReturnValue == nil {
+			p.print("return;")
+			return
+		}
+
+		p.print("return (")
+		p.expression(v.ReturnValue, 0)
+		p.print(");")
+	case *ast.CallStatement:
+		p.print("call ")
+		p.expression(v.CallValue, 0)
+		p.print(";")
+	case *ast.CommentStatement:
+		p.comment(v)
+	case *ast.ExpressionStatement:
+		p.expression(v.Expression, 0)
+		if isSimple(v.Expression) {
+			p.print(";")
+		}
+	case *ast.BadStatement:
+		p.errorf("%s: cannot print the statement with syntax errors", v.Token.Pos)
+	default:
+		p.errorf("cannot print the statement %T", stmt)
+	}
+}
+
+func (p *printer) comment(c *ast.CommentStatement) {
+	switch c.Token.Type {
+	case token.HASH:
+		p.print("#")
+	case token.LMULTICOMMENTLINE:
+		p.print("/* ", c.Value, " */")
+		return
+	default:
+		p.print("//")
+	}
+
+	// Fastly macros such as `#FASTLY recv` are kept without the space
+	if c.Value != "" && !(c.Token.Type == token.HASH && strings.HasPrefix(c.Value, "FASTLY ")) {
+		p.print(" ")
+	}
+
+	p.print(c.Value)
+}
+
+// expression prints the expression which is an operand of the operator with the precedence
+func (p *printer) expression(expr ast.Expression, precedence int) {
+	switch v := expr.(type) {
+	case *ast.Identifier:
+		p.print(v.Value)
+	case *ast.StringLiteral:
+		p.print(`"`, v.Value, `"`)
+	case *ast.IntegerLiteral:
+		if v.Token.Literal != "" {
+			p.print(v.Token.Literal)
+		} else {
+			p.print(strconv.FormatInt(v.Value, 10))
+		}
+	case *ast.BooleanLiteral:
+		p.print(strconv.FormatBool(v.Value))
+	case *ast.CIDRLiteral:
+		p.print(v.Value)
+	case *ast.PercentageLiteral:
+		p.print(v.Value)
+	case *ast.RTimeLiteral:
+		p.print(v.Value)
+	case *ast.PrefixExpression:
+		p.print(v.Operator)
+		p.operand(v.Right, prefixPrecedence)
+	case *ast.InfixExpression:
+		prec := precedences[v.Operator]
+		if precedence > prec {
+			p.print("(")
+			defer p.print(")")
+		}
+
+		p.operand(v.Left, prec)
+		p.print(" ", v.Operator, " ")
+		// the operators are left associative
+		p.operand(v.Right, prec+1)
+	case *ast.CallExpression:
+		p.expression(v.Function, 0)
+		p.print("(")
+		for i, arg := range v.Arguments {
+			if i > 0 {
+				p.print(", ")
+			}
+			p.expression(arg, 0)
+		}
+		p.print(")")
+	case *ast.IfExpression:
+		p.ifExpression(v)
+	case *ast.BlockExpression:
+		p.blockExpression(v)
+	case *ast.BadExpression:
+		p.errorf("%s: cannot print the expression with syntax errors", v.Token.Pos)
+	case nil:
+		p.errorf("cannot print the missing expression")
+	default:
+		p.errorf("cannot print the expression %T", expr)
+	}
+}
+
+// operand prints the operand, which is wrapped by the parentheses if it binds weaker than the operator
+func (p *printer) operand(expr ast.Expression, precedence int) {
+	if infix, ok := expr.(*ast.InfixExpression); ok && precedences[infix.Operator] < precedence {
+		p.print("(")
+		p.expression(expr, 0)
+		p.print(")")
+		return
+	}
+
+	p.expression(expr, precedence)
+}
+
+func (p *printer) ifExpression(expr *ast.IfExpression) {
+	p.print("if (")
+	p.expression(expr.Condition, 0)
+	p.print(") ")
+	p.block(expr.Consequence)
+
+	if expr.Alternative == nil {
+		return
+	}
+
+	p.print(" else ")
+	if alt, ok := elseIf(expr.Alternative); ok {
+		p.ifExpression(alt)
+		return
+	}
+
+	p.block(expr.Alternative)
+}
+
+func (p *printer) blockExpression(expr *ast.BlockExpression) {
+	if expr.Token.Type != token.LBRACE {
+		p.print(expr.Token.Literal)
+		for _, label := range expr.Labels {
+			p.print(" ", label)
+		}
+
+		if expr.Blocks == nil {
+			p.print(";")
+			return
+		}
+
+		p.print(" ")
+	}
+
+	if expr.Blocks == nil {
+		p.print("{}")
+		return
+	}
+
+	if isInline(expr) {
+		p.print("{ ")
+		for _, stmt := range expr.Blocks.Statements {
+			p.statement(stmt)
+			p.print(" ")
+		}
+		p.print("}")
+		return
+	}
+
+	p.block(expr.Blocks)
+}
+
+// isInline reports whether the object is printed on a line such as `{ .backend = F_origin; .weight = 1; }`.
+// Objects which only have assignments are kept on a line when they are written so.
+func isInline(expr *ast.BlockExpression) bool {
+	if expr.Token.Type != token.LBRACE || len(expr.Blocks.Statements) == 0 {
+		return false
+	}
+
+	for _, stmt := range expr.Blocks.Statements {
+		if _, ok := stmt.(*ast.AssignStatement); !ok {
+			return false
+		}
+	}
+
+	pos := expr.Token.Pos
+	return pos.IsValid() && pos.Line == expr.Blocks.Rbrace.Line
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	if block == nil {
+		p.errorf("cannot print the missing block")
+		return
+	}
+
+	if len(block.Statements) == 0 {
+		p.print("{}")
+		return
+	}
+
+	p.print("{")
+	p.newline()
+	p.depth++
+	p.statements(block.Statements)
+	p.depth--
+	p.indent()
+	p.print("}")
+}
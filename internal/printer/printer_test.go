@@ -0,0 +1,294 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"indentation": {
+			input: `backend F_origin {
+  .host = "example.com";
+      .port = "443";
+}`,
+			expected: `backend F_origin {
+	.host = "example.com";
+	.port = "443";
+}
+`,
+		},
+		"nested object": {
+			input: `backend F_origin { .probe = {
+.url = "/"; .timeout = 5s; } }`,
+			expected: `backend F_origin {
+	.probe = {
+		.url = "/";
+		.timeout = 5s;
+	}
+}
+`,
+		},
+		"object on a line": {
+			input: `backend F_origin { .probe = { .url = "/"; .timeout = 5s; } }`,
+			expected: `backend F_origin {
+	.probe = { .url = "/"; .timeout = 5s; }
+}
+`,
+		},
+		"director entries on a line": {
+			input: `director my_dir random {
+.quorum = 50%;
+{ .backend = F_a; .weight = 1; }
+}`,
+			expected: `director my_dir random {
+	.quorum = 50%;
+	{ .backend = F_a; .weight = 1; }
+}
+`,
+		},
+		"acl and table": {
+			input: `acl local { "localhost"; !"10.0.0.1"; "192.168.0.0"/24; }
+table t { "a": "b", "c": "d", }`,
+			expected: `acl local {
+	"localhost";
+	!"10.0.0.1";
+	"192.168.0.0"/24;
+}
+table t {
+	"a": "b",
+	"c": "d",
+}
+`,
+		},
+		"table without trailing comma": {
+			input: `table t { "a": "b" }`,
+			expected: `table t {
+	"a": "b",
+}
+`,
+		},
+		"blank lines are collapsed": {
+			input: `sub vcl_recv {
+set req.http.A = "a";
+
+
+
+set req.http.B = "b";
+}
+
+
+sub vcl_deliver {}`,
+			expected: `sub vcl_recv {
+	set req.http.A = "a";
+
+	set req.http.B = "b";
+}
+
+sub vcl_deliver {}
+`,
+		},
+		"if else": {
+			input: `sub vcl_recv {
+if(req.url ~ "^/a"){return(pass);}else if(req.url ~ "^/b"){unset req.http.Cookie;}else{call foo;}
+}`,
+			expected: `sub vcl_recv {
+	if (req.url ~ "^/a") {
+		return (pass);
+	} else if (req.url ~ "^/b") {
+		unset req.http.Cookie;
+	} else {
+		call foo;
+	}
+}
+`,
+		},
+		"necessary parentheses are kept": {
+			input: `sub vcl_recv {
+if ((req.http.A) && (req.http.B || req.http.C) && !(req.http.D == "d")) {}
+}`,
+			expected: `sub vcl_recv {
+	if (req.http.A && (req.http.B || req.http.C) && !(req.http.D == "d")) {}
+}
+`,
+		},
+		"comments": {
+			input: `#FASTLY recv
+#hash
+// line
+set req.http.A = "a"; # trailing
+/* multi
+   line */`,
+			expected: `#FASTLY recv
+# hash
+// line
+set req.http.A = "a"; # trailing
+/* multi
+   line */
+`,
+		},
+		"action with arguments": {
+			input: `sub vcl_recv {
+  error   403 "Forbidden";
+  restart;
+}`,
+			expected: `sub vcl_recv {
+	error 403 "Forbidden";
+	restart;
+}
+`,
+		},
+		"relational and arithmetic operators": {
+			input: `sub vcl_fetch {
+if(beresp.status>=500&&beresp.ttl<10s){set beresp.ttl=-1s;}
+set beresp.ttl = (beresp.ttl - 10s) * 2 % 3;
+set beresp.status = beresp.status - (1 - 2);
+}`,
+			expected: `sub vcl_fetch {
+	if (beresp.status >= 500 && beresp.ttl < 10s) {
+		set beresp.ttl = -1s;
+	}
+	set beresp.ttl = (beresp.ttl - 10s) * 2 % 3;
+	set beresp.status = beresp.status - (1 - 2);
+}
+`,
+		},
+		"compound set and return without action": {
+			input: `sub my_helper {
+set req.http.A+="a";
+set var.count ror= 1;
+return;
+}`,
+			expected: `sub my_helper {
+	set req.http.A += "a";
+	set var.count ror= 1;
+	return;
+}
+`,
+		},
+		"function call": {
+			input: `sub vcl_recv { set req.url = regsub( req.url , "\?.*$" , "" ); }`,
+			expected: `sub vcl_recv {
+	set req.url = regsub(req.url, "\?.*$", "");
+}
+`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, err := Format([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("Format() failed, err:%v", err)
+			}
+
+			if string(got) != tc.expected {
+				t.Fatalf("got:%q, want:%q", got, tc.expected)
+			}
+
+			// the output is already formatted
+			again, err := Format(got)
+			if err != nil {
+				t.Fatalf("Format() of the output failed, err:%v", err)
+			}
+
+			if string(again) != string(got) {
+				t.Fatalf("not idempotent, got:%q, want:%q", again, got)
+			}
+		})
+	}
+}
+
+func TestFormat_Error(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"syntax error":     {`sub vcl_recv { set req.url = ; }`, "1:30"},
+		"missing brace":    {`sub vcl_recv {`, "expected }"},
+		"unsupported item": {"sub vcl_recv {\n\tset req.http.A = \"a\" \"b\";\n}", "cannot format"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := Format([]byte(tc.input))
+			if err == nil {
+				t.Fatalf("Format() should fail")
+			}
+
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("got:%v, want:%v", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPrint_WithoutPositions(t *testing.T) {
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.CommentStatement{Token: token.Token{Type: token.HASH}, Value: "generated"},
+			&ast.ExpressionStatement{
+				Expression: &ast.BlockExpression{
+					Token:  token.Token{Type: token.BACKEND, Literal: "backend"},
+					Labels: []string{"F_origin"},
+					Blocks: &ast.BlockStatement{
+						Statements: []ast.Statement{
+							&ast.AssignStatement{
+								Name:  &ast.Identifier{Value: ".port"},
+								Value: &ast.StringLiteral{Value: "443"},
+							},
+						},
+					},
+				},
+			},
+			&ast.ExpressionStatement{
+				Expression: &ast.BlockExpression{
+					Token:  token.Token{Type: token.ACL, Literal: "acl"},
+					Labels: []string{"local"},
+					Blocks: &ast.BlockStatement{
+						Statements: []ast.Statement{
+							&ast.ExpressionStatement{Expression: &ast.StringLiteral{Value: "localhost"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := `# generated
+backend F_origin {
+	.port = "443";
+}
+
+acl local {
+	"localhost";
+}
+`
+
+	got, err := Print(program)
+	if err != nil {
+		t.Fatalf("Print() failed, err:%v", err)
+	}
+
+	if got != expected {
+		t.Fatalf("got:%q, want:%q", got, expected)
+	}
+}
+
+func TestPrint_BadStatement(t *testing.T) {
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.BadStatement{Token: token.Token{Type: token.SET, Literal: "set", Pos: token.Position{Offset: 0, Line: 1, Column: 1}}},
+		},
+	}
+
+	if _, err := Print(program); err == nil {
+		t.Fatalf("Print() should fail for the bad statement")
+	}
+}
@@ -0,0 +1,213 @@
+package regex
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Error is a problem of the regular expression in the program
+type Error struct {
+	Pos token.Position
+	Msg string
+
+	// Warning is true for PCRE-only constructs which are valid in VCL but cannot be verified
+	Warning bool
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Construct is a PCRE-only construct found in the pattern
+type Construct struct {
+	Offset int // byte offset in the pattern
+	Name   string
+}
+
+// pcreGroups are the group prefixes which Go's regexp does not support
+var pcreGroups = []struct {
+	prefix string
+	name   string
+}{
+	{"(?<=", "lookbehind"},
+	{"(?<!", "negative lookbehind"},
+	{"(?=", "lookahead"},
+	{"(?!", "negative lookahead"},
+	{"(?>", "atomic group"},
+}
+
+// PCREConstructs returns the constructs of the pattern which are supported by PCRE but not by Go,
+// such as lookaheads, backreferences and possessive quantifiers.
+func PCREConstructs(pattern string) []Construct {
+	constructs := []Construct{}
+	inClass := false
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			if next := pattern[i+1]; !inClass && next >= '1' && next <= '9' {
+				constructs = append(constructs, Construct{Offset: i, Name: "backreference"})
+			}
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+			// `]` right after `[` or `[^` is a literal
+			if strings.HasPrefix(pattern[i+1:], "^]") {
+				i += 2
+			} else if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case c == '(':
+			for _, g := range pcreGroups {
+				if strings.HasPrefix(pattern[i:], g.prefix) {
+					constructs = append(constructs, Construct{Offset: i, Name: g.name})
+					break
+				}
+			}
+		case c == '+' && i > 0 && isQuantifier(pattern, i-1):
+			constructs = append(constructs, Construct{Offset: i - 1, Name: "possessive quantifier"})
+		}
+	}
+
+	return constructs
+}
+
+// isQuantifier reports whether the byte at i ends a quantifier which is not escaped
+func isQuantifier(pattern string, i int) bool {
+	switch pattern[i] {
+	case '*', '+', '?', '}':
+	default:
+		return false
+	}
+
+	// count the preceding backslashes
+	n := 0
+	for j := i - 1; j >= 0 && pattern[j] == '\\'; j-- {
+		n++
+	}
+
+	if n%2 == 1 {
+		return false
+	}
+
+	// `a++` is possessive but the first `+` of `a+++` is already a part of the possessive quantifier
+	return pattern[i] != '+' || i == 0 || !isQuantifier(pattern, i-1)
+}
+
+// Validate parses the pattern with Go's regexp/syntax.
+// The offset is the byte offset of the problem in the pattern.
+func Validate(pattern string) (offset int, err error) {
+	if _, err := syntax.Parse(pattern, syntax.Perl); err != nil {
+		offset := 0
+		if serr, ok := err.(*syntax.Error); ok {
+			if i := strings.Index(pattern, serr.Expr); i >= 0 {
+				offset = i
+			}
+		}
+
+		return offset, err
+	}
+
+	return 0, nil
+}
+
+// Check validates the regular expressions in the program.
+// The patterns are the string literals on the right of `~` and `!~` and the second arguments of regsub and regsuball.
+// Patterns with PCRE-only constructs are reported as warnings because Go cannot verify them.
+func Check(program *ast.Program) []error {
+	errs := []error{}
+	for _, lit := range Literals(program) {
+		errs = append(errs, checkLiteral(lit)...)
+	}
+
+	return errs
+}
+
+func checkLiteral(lit *ast.StringLiteral) []error {
+	if constructs := PCREConstructs(lit.Value); len(constructs) > 0 {
+		errs := []error{}
+		for _, c := range constructs {
+			errs = append(errs, &Error{
+				Pos:     positionIn(lit, c.Offset),
+				Msg:     fmt.Sprintf("regex %q uses PCRE-only %s which cannot be verified", lit.Value, c.Name),
+				Warning: true,
+			})
+		}
+
+		return errs
+	}
+
+	if offset, err := Validate(lit.Value); err != nil {
+		return []error{&Error{
+			Pos: positionIn(lit, offset),
+			Msg: fmt.Sprintf("invalid regex %q: %s", lit.Value, err),
+		}}
+	}
+
+	return nil
+}
+
+// positionIn returns the position of the byte offset in the string literal.
+// The literal is assumed to be on a single line.
+func positionIn(lit *ast.StringLiteral, offset int) token.Position {
+	pos := lit.Token.Pos
+	if !pos.IsValid() || strings.Contains(lit.Value[:offset], "\n") {
+		return pos
+	}
+
+	// skip the opening quote
+	pos.Offset += offset + 1
+	pos.Column += offset + 1
+	return pos
+}
+
+// regexArguments are the functions and the index of the argument which is a regular expression
+var regexArguments = map[string]int{
+	"regsub":    1,
+	"regsuball": 1,
+}
+
+// Literals returns the string literals which are used as regular expressions in the program
+func Literals(program *ast.Program) []*ast.StringLiteral {
+	lits := []*ast.StringLiteral{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch v := node.(type) {
+		case *ast.InfixExpression:
+			if v.Operator != token.MATCH && v.Operator != token.NOTMATCH {
+				return true
+			}
+
+			if lit, ok := v.Right.(*ast.StringLiteral); ok {
+				lits = append(lits, lit)
+			}
+		case *ast.CallExpression:
+			ident, ok := v.Function.(*ast.Identifier)
+			if !ok {
+				return true
+			}
+
+			i, ok := regexArguments[ident.Value]
+			if !ok || i >= len(v.Arguments) {
+				return true
+			}
+
+			if lit, ok := v.Arguments[i].(*ast.StringLiteral); ok {
+				lits = append(lits, lit)
+			}
+		}
+
+		return true
+	})
+
+	return lits
+}
@@ -0,0 +1,95 @@
+package regex
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func TestPCREConstructs(t *testing.T) {
+	testCases := map[string]struct {
+		pattern  string
+		expected []Construct
+	}{
+		"with go compatible":         {`^/foo/(bar|baz)+\.html$`, []Construct{}},
+		"with lookahead":             {`^/(?=foo)`, []Construct{{2, "lookahead"}}},
+		"with negative lookahead":    {`^/(?!foo)`, []Construct{{2, "negative lookahead"}}},
+		"with lookbehind":            {`(?<=foo)bar`, []Construct{{0, "lookbehind"}}},
+		"with negative lookbehind":   {`(?<!foo)bar`, []Construct{{0, "negative lookbehind"}}},
+		"with atomic group":          {`(?>foo)bar`, []Construct{{0, "atomic group"}}},
+		"with backreference":         {`(a)\1`, []Construct{{3, "backreference"}}},
+		"with escaped backslash":     {`\\1`, []Construct{}},
+		"with possessive quantifier": {`a++b*+c{2}+`, []Construct{{1, "possessive quantifier"}, {4, "possessive quantifier"}, {9, "possessive quantifier"}}},
+		"with escaped plus":          {`\++`, []Construct{}},
+		"with character class":       {`[(?=\1]+`, []Construct{}},
+		"with bracket in class":      {`[]+]+`, []Construct{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			constructs := PCREConstructs(tc.pattern)
+			if len(constructs) != len(tc.expected) {
+				t.Fatalf("constructs length failed, got:%v, want:%v", constructs, tc.expected)
+			}
+
+			for i, c := range constructs {
+				if c != tc.expected[i] {
+					t.Fatalf("construct[%d] failed, got:%v, want:%v", i, c, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected []string
+		warning  []bool
+	}{
+		"with valid regex": {`sub vcl_recv {
+	if (req.url ~ "^/foo" && req.http.Host !~ "(?i)example\.com$") {
+		set req.url = regsub(req.url, "^/foo/(.*)", "/\1");
+	}
+}`, []string{}, []bool{}},
+		"with invalid match": {`sub vcl_recv {
+	if (req.url ~ "^/(foo") {}
+}`, []string{"2:17: invalid regex \"^/(foo\": error parsing regexp: missing closing ): `^/(foo`"}, []bool{false}},
+		"with invalid regsub": {`sub vcl_recv {
+	set req.url = regsuball(req.url, "a**", "b");
+}`, []string{"2:37: invalid regex \"a**\": error parsing regexp: invalid nested repetition operator: `**`"}, []bool{false}},
+		"with pcre construct": {`sub vcl_recv {
+	if (req.url !~ "^/(?!admin)(a)\1") {}
+}`, []string{"2:20: regex \"^/(?!admin)(a)\\\\1\" uses PCRE-only negative lookahead which cannot be verified", "2:32: regex \"^/(?!admin)(a)\\\\1\" uses PCRE-only backreference which cannot be verified"}, []bool{true, true}},
+		"with acl": {`sub vcl_recv {
+	if (client.ip ~ purge_ip) {}
+}`, []string{}, []bool{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			errs := Check(program)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("errors length failed, got:%v, want:%v", errs, tc.expected)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.expected[i] {
+					t.Fatalf("error[%d] failed, got:%s, want:%s", i, err, tc.expected[i])
+				}
+
+				if warning := err.(*Error).Warning; warning != tc.warning[i] {
+					t.Fatalf("error[%d] warning failed, got:%v, want:%v", i, warning, tc.warning[i])
+				}
+			}
+		})
+	}
+}
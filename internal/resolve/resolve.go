@@ -0,0 +1,372 @@
+package resolve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Kind is a kind of the declared symbol
+type Kind int
+
+const (
+	Backend Kind = iota + 1
+	Director
+	ACL
+	Table
+	Subroutine
+	Penaltybox
+	Ratecounter
+	Probe
+)
+
+var kindNames = map[Kind]string{
+	Backend:     "backend",
+	Director:    "director",
+	ACL:         "acl",
+	Table:       "table",
+	Subroutine:  "sub",
+	Penaltybox:  "penaltybox",
+	Ratecounter: "ratecounter",
+	Probe:       "probe",
+}
+
+// String returns the VCL keyword of the kind
+func (k Kind) String() string {
+	if n, ok := kindNames[k]; ok {
+		return n
+	}
+
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// kinds maps the block type of the declaration to the kind
+var kinds = map[string]Kind{
+	"backend":     Backend,
+	"director":    Director,
+	"acl":         ACL,
+	"table":       Table,
+	"sub":         Subroutine,
+	"penaltybox":  Penaltybox,
+	"ratecounter": Ratecounter,
+	"probe":       Probe,
+}
+
+// namespace returns the namespace of the kind.
+// Backends and directors share the same namespace because both of them can be assigned to req.backend.
+func namespace(k Kind) Kind {
+	if k == Director {
+		return Backend
+	}
+
+	return k
+}
+
+// Symbol is a declaration of the program
+type Symbol struct {
+	Kind Kind
+	Name string
+	Pos  token.Position
+
+	// Decls are the declarations of the symbol.
+	// Built-in subroutines like vcl_recv can be declared multiple times and they are concatenated.
+	Decls []*ast.BlockExpression
+
+	// References are the resolved references to this symbol
+	References []*Reference
+}
+
+// Reference is an use of the symbol
+type Reference struct {
+	Name  string
+	Kinds []Kind // kinds that the reference can be resolved to
+	Pos   token.Position
+	Node  ast.Node
+
+	// Scope is the declaration which encloses the reference, nil at the top level
+	Scope *Symbol
+
+	// Symbol is the resolved declaration, nil if undefined
+	Symbol *Symbol
+}
+
+// SymbolTable is a symbol table of the program
+type SymbolTable struct {
+	Symbols    []*Symbol
+	References []*Reference
+
+	symbols map[Kind]map[string]*Symbol
+}
+
+// Lookup returns the symbol declared by the kind and name, nil if not found
+func (t *SymbolTable) Lookup(kind Kind, name string) *Symbol {
+	sym := t.symbols[namespace(kind)][name]
+	if sym == nil || sym.Kind != kind {
+		return nil
+	}
+
+	return sym
+}
+
+// Error is an error of the name resolution
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Resolve builds the symbol table of the program and links references to their declarations.
+// The returned errors are undefined and duplicate symbols.
+func Resolve(program *ast.Program) (*SymbolTable, []error) {
+	r := &resolver{
+		table: &SymbolTable{
+			Symbols:    []*Symbol{},
+			References: []*Reference{},
+			symbols:    map[Kind]map[string]*Symbol{},
+		},
+		errs: []error{},
+	}
+
+	r.declare(program.Statements)
+	r.resolveStatements(program.Statements, nil)
+	r.link()
+
+	return r.table, r.errs
+}
+
+type resolver struct {
+	table *SymbolTable
+	errs  []error
+}
+
+func (r *resolver) errorf(pos token.Position, format string, args ...interface{}) {
+	r.errs = append(r.errs, &Error{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	})
+}
+
+// declaration returns the top level declaration with its kind
+func declaration(stmt ast.Statement) (*ast.BlockExpression, Kind, bool) {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return nil, 0, false
+	}
+
+	block, ok := es.Expression.(*ast.BlockExpression)
+	if !ok || len(block.Labels) == 0 {
+		return nil, 0, false
+	}
+
+	kind, ok := kinds[block.TokenLiteral()]
+	return block, kind, ok
+}
+
+func (r *resolver) declare(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		block, kind, ok := declaration(stmt)
+		if !ok {
+			continue
+		}
+
+		name := block.Labels[0]
+		ns := namespace(kind)
+		if r.table.symbols[ns] == nil {
+			r.table.symbols[ns] = map[string]*Symbol{}
+		}
+
+		if prev, ok := r.table.symbols[ns][name]; ok {
+			if kind == Subroutine && prev.Kind == Subroutine && IsBuiltinSubroutine(name) {
+				prev.Decls = append(prev.Decls, block)
+				continue
+			}
+
+			r.errorf(block.Token.Pos, "%s %s redeclared, previous declaration at %s", kind, name, prev.Pos)
+			continue
+		}
+
+		sym := &Symbol{
+			Kind:       kind,
+			Name:       name,
+			Pos:        block.Token.Pos,
+			Decls:      []*ast.BlockExpression{block},
+			References: []*Reference{},
+		}
+
+		r.table.symbols[ns][name] = sym
+		r.table.Symbols = append(r.table.Symbols, sym)
+	}
+}
+
+// IsBuiltinSubroutine reports whether the name is a subroutine of VCL state like vcl_recv
+func IsBuiltinSubroutine(name string) bool {
+	return strings.HasPrefix(name, "vcl_")
+}
+
+func (r *resolver) reference(ident *ast.Identifier, scope *Symbol, kinds ...Kind) {
+	r.table.References = append(r.table.References, &Reference{
+		Name:  ident.Value,
+		Kinds: kinds,
+		Pos:   ident.Token.Pos,
+		Node:  ident,
+		Scope: scope,
+	})
+}
+
+func (r *resolver) resolveStatements(stmts []ast.Statement, scope *Symbol) {
+	for _, stmt := range stmts {
+		r.resolveStatement(stmt, scope)
+	}
+}
+
+func (r *resolver) resolveStatement(stmt ast.Statement, scope *Symbol) {
+	switch v := stmt.(type) {
+	case *ast.ExpressionStatement:
+		if block, kind, ok := declaration(v); ok && scope == nil {
+			sym := r.table.Lookup(kind, block.Labels[0])
+			if block.Blocks != nil {
+				r.resolveStatements(block.Blocks.Statements, sym)
+			}
+			return
+		}
+
+		r.resolveExpression(v.Expression, scope)
+	case *ast.BlockStatement:
+		r.resolveStatements(v.Statements, scope)
+	case *ast.CallStatement:
+		if ident, ok := v.CallValue.(*ast.Identifier); ok {
+			r.reference(ident, scope, Subroutine)
+			return
+		}
+
+		r.resolveExpression(v.CallValue, scope)
+	case *ast.SetStatement:
+		if ident, ok := v.Value.(*ast.Identifier); ok && isBackendVariable(v.Name.Value) {
+			r.reference(ident, scope, Backend, Director)
+			return
+		}
+
+		r.resolveExpression(v.Value, scope)
+	case *ast.AssignStatement:
+		if ident, ok := v.Value.(*ast.Identifier); ok {
+			switch v.Name.Value {
+			case ".backend":
+				r.reference(ident, scope, Backend, Director)
+			case ".probe":
+				r.reference(ident, scope, Probe)
+			}
+			return
+		}
+
+		r.resolveExpression(v.Value, scope)
+	case *ast.ReturnStatement:
+		r.resolveExpression(v.ReturnValue, scope)
+	}
+}
+
+func (r *resolver) resolveExpression(expr ast.Expression, scope *Symbol) {
+	switch v := expr.(type) {
+	case *ast.InfixExpression:
+		if ident, ok := v.Right.(*ast.Identifier); ok {
+			switch v.Operator {
+			case token.MATCH, token.NOTMATCH:
+				r.resolveExpression(v.Left, scope)
+				r.reference(ident, scope, ACL)
+				return
+			case token.EQUAL, token.NOTEQUAL:
+				if left, ok := v.Left.(*ast.Identifier); ok && isBackendVariable(left.Value) {
+					r.reference(ident, scope, Backend, Director)
+					return
+				}
+			}
+		}
+
+		r.resolveExpression(v.Left, scope)
+		r.resolveExpression(v.Right, scope)
+	case *ast.PrefixExpression:
+		r.resolveExpression(v.Right, scope)
+	case *ast.IfExpression:
+		r.resolveExpression(v.Condition, scope)
+		if v.Consequence != nil {
+			r.resolveStatements(v.Consequence.Statements, scope)
+		}
+
+		if v.Alternative != nil {
+			r.resolveStatements(v.Alternative.Statements, scope)
+		}
+	case *ast.BlockExpression:
+		if v.Blocks != nil {
+			r.resolveStatements(v.Blocks.Statements, scope)
+		}
+	case *ast.CallExpression:
+		r.resolveCallExpression(v, scope)
+	}
+}
+
+func (r *resolver) resolveCallExpression(expr *ast.CallExpression, scope *Symbol) {
+	var params map[int]Kind
+	if fn, ok := expr.Function.(*ast.Identifier); ok {
+		params = functionParams(fn.Value)
+	}
+
+	for i, arg := range expr.Arguments {
+		if kind, ok := params[i]; ok {
+			if ident, ok := arg.(*ast.Identifier); ok {
+				r.reference(ident, scope, kind)
+				continue
+			}
+		}
+
+		r.resolveExpression(arg, scope)
+	}
+}
+
+// functionParams returns the index of the arguments which refer to a declaration
+func functionParams(name string) map[int]Kind {
+	switch {
+	case strings.HasPrefix(name, "table."):
+		return map[int]Kind{0: Table}
+	case name == "ratelimit.penaltybox_add", name == "ratelimit.penaltybox_has":
+		return map[int]Kind{0: Penaltybox}
+	case name == "ratelimit.ratecounter_increment":
+		return map[int]Kind{0: Ratecounter}
+	case name == "ratelimit.check_rate":
+		return map[int]Kind{1: Ratecounter, 5: Penaltybox}
+	case name == "ratelimit.check_rates":
+		return map[int]Kind{1: Ratecounter, 5: Ratecounter, 9: Penaltybox}
+	}
+
+	return nil
+}
+
+// isBackendVariable reports whether the variable holds a backend such as req.backend
+func isBackendVariable(name string) bool {
+	return strings.HasSuffix(name, ".backend") || strings.HasSuffix(name, ".backend_hint")
+}
+
+func (r *resolver) link() {
+	for _, ref := range r.table.References {
+		for _, kind := range ref.Kinds {
+			if sym := r.table.Lookup(kind, ref.Name); sym != nil {
+				ref.Symbol = sym
+				sym.References = append(sym.References, ref)
+				break
+			}
+		}
+
+		if ref.Symbol == nil {
+			names := make([]string, len(ref.Kinds))
+			for i, kind := range ref.Kinds {
+				names[i] = kind.String()
+			}
+
+			r.errorf(ref.Pos, "undefined %s %s", strings.Join(names, " or "), ref.Name)
+		}
+	}
+}
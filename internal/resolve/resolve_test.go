@@ -0,0 +1,161 @@
+package resolve
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+const testProgram = `acl purge_ip {
+	"localhost";
+}
+
+backend F_origin {
+	.host = "example.com";
+	.probe = origin_probe;
+}
+
+probe origin_probe {
+	.url = "/health";
+}
+
+director my_dir random {
+	{ .backend = F_origin; .weight = 1; }
+}
+
+table redirects {
+	"/old": "/new",
+}
+
+penaltybox banned {}
+
+ratecounter requests {}
+
+sub set_backend {
+	set req.backend = my_dir;
+}
+
+sub vcl_recv {
+	if (client.ip ~ purge_ip) {
+		return (purge);
+	}
+
+	if (ratelimit.penaltybox_has(banned, client.ip)) {
+		error 429;
+	}
+
+	set req.http.X-Count = ratelimit.ratecounter_increment(requests, client.ip, 1);
+
+	set req.url = table.lookup(redirects, req.url, req.url);
+	call set_backend;
+	return (lookup);
+}
+
+sub vcl_recv {
+	if (req.backend == F_origin) {
+		call set_backend;
+	}
+}
+`
+
+func TestResolve(t *testing.T) {
+	l := lexer.NewLexer(testProgram)
+	p := parser.NewParser(l)
+	program := p.ParseProgram()
+
+	table, errs := Resolve(program)
+	if len(errs) > 0 {
+		t.Fatalf("resolve has errors, errs:%v", errs)
+	}
+
+	testCases := map[string]struct {
+		kind               Kind
+		name               string
+		expectedReferences int
+	}{
+		"acl":                 {ACL, "purge_ip", 1},
+		"backend":             {Backend, "F_origin", 2},
+		"probe":               {Probe, "origin_probe", 1},
+		"director":            {Director, "my_dir", 1},
+		"table":               {Table, "redirects", 1},
+		"penaltybox":          {Penaltybox, "banned", 1},
+		"ratecounter":         {Ratecounter, "requests", 1},
+		"sub":                 {Subroutine, "set_backend", 2},
+		"builtin sub":         {Subroutine, "vcl_recv", 0},
+		"director as backend": {Backend, "my_dir", -1},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sym := table.Lookup(tc.kind, tc.name)
+			if tc.expectedReferences < 0 {
+				if sym != nil {
+					t.Fatalf("lookup should not find %s %s, got:%#v", tc.kind, tc.name, sym)
+				}
+				return
+			}
+
+			if sym == nil {
+				t.Fatalf("lookup failed to find %s %s", tc.kind, tc.name)
+			}
+
+			if len(sym.References) != tc.expectedReferences {
+				t.Fatalf("references of %s %s wrong, got:%d, want:%d", tc.kind, tc.name, len(sym.References), tc.expectedReferences)
+			}
+		})
+	}
+
+	recv := table.Lookup(Subroutine, "vcl_recv")
+	if len(recv.Decls) != 2 {
+		t.Fatalf("vcl_recv declarations wrong, got:%d, want:%d", len(recv.Decls), 2)
+	}
+
+	for _, ref := range table.Lookup(Subroutine, "set_backend").References {
+		if ref.Scope != recv {
+			t.Fatalf("reference scope wrong, got:%v, want:%v", ref.Scope, recv)
+		}
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		input       string
+		expectedErr string
+	}{
+		"with undefined sub": {`sub vcl_recv {
+	call missing;
+}`, "2:7: undefined sub missing"},
+		"with undefined acl": {`sub vcl_recv {
+	if (client.ip ~ purge_ip) {}
+}`, "2:18: undefined acl purge_ip"},
+		"with undefined backend": {`sub vcl_recv {
+	set req.backend = F_missing;
+}`, "2:20: undefined backend or director F_missing"},
+		"with undefined table": {`sub vcl_recv {
+	set req.url = table.lookup(missing, req.url);
+}`, "2:29: undefined table missing"},
+		"with duplicate backend": {`backend F_x {}
+director F_x random {}`, "2:1: director F_x redeclared, previous declaration at 1:1"},
+		"with duplicate sub": {`sub foo {}
+sub foo {}`, "2:1: sub foo redeclared, previous declaration at 1:1"},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+
+			_, errs := Resolve(program)
+			if len(errs) != 1 {
+				t.Fatalf("resolve errors length wrong, got:%v, want:%s", errs, tc.expectedErr)
+			}
+
+			if !strings.Contains(errs[0].Error(), tc.expectedErr) {
+				t.Fatalf("resolve error wrong, got:%s, want:%s", errs[0], tc.expectedErr)
+			}
+		})
+	}
+}
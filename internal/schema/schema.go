@@ -1,5 +1,10 @@
 package schema
 
+import (
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
 // File is the root of the data structure
 type File struct {
 	Body Body
@@ -20,11 +25,22 @@ type Flats []interface{}
 // Comments are comment lines
 type Comments []string
 
+// Entries are anonymous entries of a director such as `{ .backend = F_x; .weight = 1; }`
+type Entries []*DirectorBackendEntry
+
+// DirectorBackendEntry is a single backend entry of a director
+type DirectorBackendEntry struct {
+	Attributes Attributes
+	Statements []ast.Statement // raw statements of the entry
+}
+
 // Block ais a structure which contains block header, labels and body
 type Block struct {
 	Type   string
 	Labels []string
 	Body   Body
+	Expr   *ast.BlockExpression // raw block, whose Blocks is nil if the block has parse errors
+	Pos    token.Position
 }
 
 // BodySchema represents the desired structure of a body.
@@ -37,6 +53,8 @@ type BodySchema struct {
 type Attribute struct {
 	Name  string
 	Value interface{}
+	Expr  ast.Expression // raw value such as *ast.RTimeLiteral
+	Pos   token.Position
 }
 
 // BodyContent is a content from body
@@ -45,6 +63,8 @@ type BodyContent struct {
 	Blocks     Blocks
 	Flats      Flats
 	Comments   Comments
+	Entries    Entries
+	Statements []ast.Statement // raw statements of the body
 }
 
 // AttributeSchema is the desired attribute
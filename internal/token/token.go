@@ -1,9 +1,33 @@
 package token
 
+import "fmt"
+
 // Token defineds a single VCL token
 type Token struct {
 	Type    Type
 	Literal string
+	Pos     Position
+}
+
+// Position is a location of the token in the source
+type Position struct {
+	Offset int // byte offset, starting at 0
+	Line   int // line number, starting at 1
+	Column int // column number in bytes, starting at 1
+}
+
+// IsValid reports whether the position is known
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as "line:column"
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 // Type is a set of lexical tokens of the VCL
@@ -16,18 +40,41 @@ const (
 	IDENT      = "IDENT"
 	INT        = "INT"
 	PERCENTAGE = "PERCENTAGE"
+	RTIME      = "RTIME"
 	STRING     = "STRING"
 	CIDR       = "CIDR"
 	TRUE       = "TRUE"
 	FALSE      = "FALSE"
 
-	ASSIGN = "="
-	MATCH  = "~"
-	PLUS   = "+"
-	BANG   = "!"
-	EQUAL  = "=="
-	AND    = "&&"
-	OR     = "||"
+	ASSIGN   = "="
+	MATCH    = "~"
+	NOTMATCH = "!~"
+	PLUS     = "+"
+	MINUS    = "-"
+	ASTERISK = "*"
+	SLASH    = "/"
+	LT       = "<"
+	GT       = ">"
+	LE       = "<="
+	GE       = ">="
+	BANG     = "!"
+	EQUAL    = "=="
+	NOTEQUAL = "!="
+	AND      = "&&"
+	OR       = "||"
+
+	ADDASSIGN    = "+="
+	SUBASSIGN    = "-="
+	MULASSIGN    = "*="
+	DIVASSIGN    = "/="
+	MODASSIGN    = "%="
+	BITORASSIGN  = "|="
+	BITANDASSIGN = "&="
+	BITXORASSIGN = "^="
+	LSHIFTASSIGN = "<<="
+	RSHIFTASSIGN = ">>="
+	ANDASSIGN    = "&&="
+	ORASSIGN     = "||="
 
 	COMMA     = ","
 	SEMICOLON = ";"
@@ -46,14 +93,19 @@ const (
 	IF   = "IF"
 	ELSE = "ELSE"
 
-	RETURN     = "RETURN"
-	IMPORT     = "IMPORT"
-	TABLE      = "TABLE"
-	ACL        = "ACL"
-	BACKEND    = "BACKEND"
-	SUBROUTINE = "SUBROUTINE"
-	CALL       = "CALL"
-	DIRECTOR   = "DIRECTOR"
+	RETURN      = "RETURN"
+	IMPORT      = "IMPORT"
+	TABLE       = "TABLE"
+	ACL         = "ACL"
+	BACKEND     = "BACKEND"
+	SUBROUTINE  = "SUBROUTINE"
+	CALL        = "CALL"
+	DIRECTOR    = "DIRECTOR"
+	SET         = "SET"
+	UNSET       = "UNSET"
+	PENALTYBOX  = "PENALTYBOX"
+	RATECOUNTER = "RATECOUNTER"
+	PROBE       = "PROBE"
 )
 
 // NewToken returns a token from token type and current char input
@@ -79,6 +131,22 @@ var keywords = map[string]Type{
 	"director": DIRECTOR,
 }
 
+// statementKeywords are only keywords at the start of a statement or a declaration
+// so that they can still be used as identifiers such as `probe` of a backend.
+var statementKeywords = map[string]Type{
+	"set":         SET,
+	"unset":       UNSET,
+	"penaltybox":  PENALTYBOX,
+	"ratecounter": RATECOUNTER,
+	"probe":       PROBE,
+}
+
+// LookupStatementKeyword returns the keyword if the identifier starts a statement or a declaration.
+func LookupStatementKeyword(indent string) (Type, bool) {
+	tokenType, ok := statementKeywords[indent]
+	return tokenType, ok
+}
+
 // LookupIndent returns keywork if hit from the identifier.
 func LookupIndent(indent string) Type {
 	if tokenType, ok := keywords[indent]; ok {
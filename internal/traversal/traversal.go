@@ -1,27 +1,58 @@
 package traversal
 
 import (
+	"fmt"
+
 	"github.com/KeisukeYamashita/go-vcl/internal/ast"
 	"github.com/KeisukeYamashita/go-vcl/internal/schema"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
 )
 
+// Error is an error of the statement which cannot be converted to the schema
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
 // Content retrives from ast.Program
-func Content(prog *ast.Program) *schema.BodyContent {
-	b := convertBody(prog.Statements)
-	return b
+func Content(prog *ast.Program) (*schema.BodyContent, []error) {
+	return convertBody(prog.Statements)
 }
 
-// BodyContent retrives body content from body
+// BodyContent retrives body content from body.
+// It returns an empty content if the body is not a content.
 func BodyContent(body schema.Body) *schema.BodyContent {
-	return body.(*schema.BodyContent)
+	if content, ok := body.(*schema.BodyContent); ok && content != nil {
+		return content
+	}
+
+	return &schema.BodyContent{
+		Attributes: schema.Attributes{},
+	}
+}
+
+// blockStatements returns the statements of the block which is nil when the block has parse errors
+func blockStatements(expr *ast.BlockExpression) []ast.Statement {
+	if expr.Blocks == nil {
+		return nil
+	}
+
+	return expr.Blocks.Statements
 }
 
 // Contents will ast.Program to schema
-func convertBody(stmts []ast.Statement) *schema.BodyContent {
+func convertBody(stmts []ast.Statement) (*schema.BodyContent, []error) {
+	errs := []error{}
 	attrs := make(map[string]*schema.Attribute)
 	var blocks schema.Blocks
 	flats := []interface{}{}
 	comments := []string{}
+	entries := schema.Entries{}
 
 	for _, stmt := range stmts {
 		switch v := stmt.(type) {
@@ -35,28 +66,39 @@ func convertBody(stmts []ast.Statement) *schema.BodyContent {
 				value = lit.Value
 			case *ast.PercentageLiteral:
 				value = lit.Value
+			case *ast.RTimeLiteral:
+				value = lit.Value
 			case *ast.BooleanLiteral:
 				value = lit.Value
 			case *ast.IntegerLiteral:
 				value = lit.Value
 			case *ast.BlockExpression:
 				isBlock = true
-				body := convertBody(lit.Blocks.Statements)
+				body, bodyErrs := convertBody(blockStatements(lit))
+				errs = append(errs, bodyErrs...)
 				block := &schema.Block{
 					Body: body,
+					Expr: lit,
+					Pos:  v.Token.Pos,
 				}
 				block.Type = v.TokenLiteral()
 				blocks = append(blocks, block)
 			case *ast.Identifier:
 				value = lit.Value
 			default:
-				panic("cannot pass invalid argument which is no a literal")
+				errs = append(errs, &Error{
+					Pos: v.Name.Token.Pos,
+					Msg: fmt.Sprintf("value of %s must be a literal", v.Name.Value),
+				})
+				continue
 			}
 
 			if isBlock == false {
 				attrs[v.Name.Value] = &schema.Attribute{
 					Name:  v.Name.Value,
 					Value: value,
+					Expr:  v.Value,
+					Pos:   v.Name.Token.Pos,
 				}
 			}
 		case *ast.AssignFieldStatement:
@@ -69,13 +111,18 @@ func convertBody(stmts []ast.Statement) *schema.BodyContent {
 			attrs[v.Name.Value] = &schema.Attribute{
 				Name:  v.Name.Value,
 				Value: value,
+				Expr:  v.Value,
+				Pos:   v.Name.Token.Pos,
 			}
 		case *ast.ExpressionStatement:
 			switch expr := v.Expression.(type) {
 			case *ast.BlockExpression:
-				body := convertBody(expr.Blocks.Statements)
+				body, bodyErrs := convertBody(blockStatements(expr))
+				errs = append(errs, bodyErrs...)
 				block := &schema.Block{
 					Body: body,
+					Expr: expr,
+					Pos:  expr.Token.Pos,
 				}
 
 				block.Type = expr.TokenLiteral()
@@ -84,8 +131,12 @@ func convertBody(stmts []ast.Statement) *schema.BodyContent {
 				}
 
 				if block.Type == "{" {
-					// this is flatten block
+					// this is flatten block which is used as a backend entry of the director
 					flats = append(flats, block)
+					entries = append(entries, &schema.DirectorBackendEntry{
+						Attributes: body.Attributes,
+						Statements: body.Statements,
+					})
 				} else {
 					blocks = append(blocks, block)
 				}
@@ -108,7 +159,9 @@ func convertBody(stmts []ast.Statement) *schema.BodyContent {
 		Blocks:     blocks,
 		Flats:      flats,
 		Comments:   comments,
+		Entries:    entries,
+		Statements: stmts,
 	}
 
-	return body
+	return body, errs
 }
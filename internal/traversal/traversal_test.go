@@ -36,7 +36,11 @@ func TestContents(t *testing.T) {
 		p := parser.NewParser(l)
 
 		program := p.ParseProgram()
-		content := Content(program)
+		content, errs := Content(program)
+		if len(errs) > 0 {
+			t.Fatalf("contents has errors[testcase:%d], errs:%v", n, errs)
+		}
+
 		if len(content.Attributes) != tc.expectedAttrCount {
 			t.Fatalf("contents.Attributes length failed[testcase:%d], got:%d, want:%d", n, len(content.Attributes), tc.expectedAttrCount)
 		}
@@ -75,7 +79,11 @@ func TestConvertBody(t *testing.T) {
 		l := lexer.NewLexer(tc.input)
 		p := parser.NewParser(l)
 		program := p.ParseProgram()
-		content := convertBody(program.Statements)
+		content, errs := convertBody(program.Statements)
+		if len(errs) > 0 {
+			t.Fatalf("convertBody has errors[testcase:%d], errs:%v", n, errs)
+		}
+
 		if len(content.Attributes) != tc.expectedAttrCount {
 			t.Fatalf("contents.Attributes length failed[testcase:%d], got:%d, want:%d", n, len(content.Attributes), tc.expectedAttrCount)
 		}
@@ -85,3 +93,73 @@ func TestConvertBody(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertBody_Entries(t *testing.T) {
+	testCases := map[string]struct {
+		input                string
+		expectedEntriesCount int
+	}{
+		"with no entries":       {`director my_dir random { .retries = 3; }`, 0},
+		"with single entry":     {`director my_dir random { { .backend = F_backend1; .weight = 1; } }`, 1},
+		"with multiple entries": {`director my_dir fallback { { .backend = F_backend1; } { .backend = F_backend2; } }`, 2},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			content, errs := convertBody(program.Statements)
+			if len(errs) > 0 {
+				t.Fatalf("convertBody has errors, errs:%v", errs)
+			}
+
+			if len(content.Blocks) != 1 {
+				t.Fatalf("contents.Blocks length failed, got:%d, want:%d", len(content.Blocks), 1)
+			}
+
+			director := BodyContent(content.Blocks[0].Body)
+			if len(director.Entries) != tc.expectedEntriesCount {
+				t.Fatalf("director.Entries length failed, got:%d, want:%d", len(director.Entries), tc.expectedEntriesCount)
+			}
+
+			for _, entry := range director.Entries {
+				if entry.Attributes[".backend"] == nil {
+					t.Fatalf("entry does not have .backend attribute, got:%#v", entry.Attributes)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertBody_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected []string
+	}{
+		"with call expression value": {`x = foo(1);`, []string{"1:1: value of x must be a literal"}},
+		"with nested invalid value": {`backend F_x {
+	.host = "example.com";
+	.port = req.url + "a";
+}`, []string{"3:2: value of .port must be a literal"}},
+		"with unclosed block": {`backend F_x`, []string{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			_, errs := convertBody(program.Statements)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("errors length failed, got:%v, want:%v", errs, tc.expected)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.expected[i] {
+					t.Fatalf("error[%d] failed, got:%s, want:%s", i, err, tc.expected[i])
+				}
+			}
+		})
+	}
+}
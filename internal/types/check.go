@@ -0,0 +1,402 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/resolve"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Error is an error of the type check
+type Error struct {
+	Pos token.Position
+	Msg string
+
+	// Soft is true for implicit conversions which are valid but may be unintended
+	Soft bool
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// symbolTypes maps the kind of the declarations to the type of the identifier which refers to it
+var symbolTypes = map[resolve.Kind]Type{
+	resolve.Backend:     Backend,
+	resolve.Director:    Backend,
+	resolve.ACL:         ACL,
+	resolve.Table:       Table,
+	resolve.Penaltybox:  Penaltybox,
+	resolve.Ratecounter: Ratecounter,
+}
+
+// Check checks the types of the expressions in the program.
+// It reports type mismatches, implicit conversions as soft errors and assignments to read-only variables.
+func Check(program *ast.Program) []error {
+	table, _ := resolve.Resolve(program)
+
+	c := &checker{
+		symbols: map[*ast.Identifier]*resolve.Symbol{},
+		errs:    []error{},
+	}
+
+	for _, ref := range table.References {
+		if ident, ok := ref.Node.(*ast.Identifier); ok && ref.Symbol != nil {
+			c.symbols[ident] = ref.Symbol
+		}
+	}
+
+	c.checkStatements(program.Statements)
+	return c.errs
+}
+
+type checker struct {
+	symbols map[*ast.Identifier]*resolve.Symbol
+	errs    []error
+}
+
+func (c *checker) errorf(pos token.Position, format string, args ...interface{}) {
+	c.errs = append(c.errs, &Error{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) softErrorf(pos token.Position, format string, args ...interface{}) {
+	c.errs = append(c.errs, &Error{
+		Pos:  pos,
+		Msg:  fmt.Sprintf(format, args...),
+		Soft: true,
+	})
+}
+
+func (c *checker) checkStatements(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		c.checkStatement(stmt)
+	}
+}
+
+func (c *checker) checkStatement(stmt ast.Statement) {
+	switch v := stmt.(type) {
+	case *ast.SetStatement:
+		typ := c.typeOf(v.Value)
+
+		variable, ok := LookupVariable(v.Name.Value)
+		if !ok {
+			return
+		}
+
+		if variable.ReadOnly {
+			c.errorf(v.Name.Token.Pos, "cannot assign to read-only variable %s", v.Name.Value)
+			return
+		}
+
+		// compound operators such as += apply the operator to the current value
+		if operator := strings.TrimSuffix(v.Operator, "="); operator != "" {
+			typ = c.operatorType(v.Name.Token.Pos, operator, variable.Type, typ)
+		}
+
+		c.assign(v.Value, typ, variable.Type, "assignment to "+v.Name.Value)
+	case *ast.UnsetStatement:
+		if variable, ok := LookupVariable(v.Name.Value); ok && variable.ReadOnly {
+			c.errorf(v.Name.Token.Pos, "cannot unset read-only variable %s", v.Name.Value)
+		}
+	case *ast.ExpressionStatement:
+		c.checkExpression(v.Expression)
+	case *ast.BlockStatement:
+		c.checkStatements(v.Statements)
+	}
+}
+
+// checkExpression checks the expression used as a statement
+func (c *checker) checkExpression(expr ast.Expression) {
+	switch v := expr.(type) {
+	case *ast.IfExpression:
+		if typ := c.typeOf(v.Condition); !isCondition(typ) {
+			c.errorf(position(v.Condition), "non-boolean condition %s in if", typ)
+		}
+
+		if v.Consequence != nil {
+			c.checkStatements(v.Consequence.Statements)
+		}
+
+		if v.Alternative != nil {
+			c.checkStatements(v.Alternative.Statements)
+		}
+	case *ast.BlockExpression:
+		if v.Blocks != nil {
+			c.checkStatements(v.Blocks.Statements)
+		}
+	default:
+		c.typeOf(expr)
+	}
+}
+
+// isCondition reports whether the type can be used as a condition.
+// STRING is tested whether it is set such as `if (req.http.Cookie)`.
+func isCondition(typ Type) bool {
+	return typ == Bool || typ == String || typ == Invalid
+}
+
+// assign checks the value of the type from can be used as the type to
+func (c *checker) assign(expr ast.Expression, from, to Type, context string) {
+	ok, implicit := assignable(from, to)
+	switch {
+	case !ok:
+		c.errorf(position(expr), "cannot use %s as %s in %s", from, to, context)
+	case implicit:
+		c.softErrorf(position(expr), "implicit conversion of %s to %s in %s", from, to, context)
+	}
+}
+
+// typeOf returns the type of the expression and checks its operands
+func (c *checker) typeOf(expr ast.Expression) Type {
+	switch v := expr.(type) {
+	case *ast.StringLiteral:
+		return String
+	case *ast.IntegerLiteral:
+		return Integer
+	case *ast.BooleanLiteral:
+		return Bool
+	case *ast.RTimeLiteral:
+		return RTime
+	case *ast.CIDRLiteral:
+		return CIDR
+	case *ast.PercentageLiteral:
+		return Float
+	case *ast.Identifier:
+		return c.identifierType(v)
+	case *ast.PrefixExpression:
+		return c.prefixType(v)
+	case *ast.InfixExpression:
+		return c.infixType(v)
+	case *ast.CallExpression:
+		return c.callType(v)
+	}
+
+	return Invalid
+}
+
+func (c *checker) identifierType(ident *ast.Identifier) Type {
+	if variable, ok := LookupVariable(ident.Value); ok {
+		return variable.Type
+	}
+
+	if sym, ok := c.symbols[ident]; ok {
+		return symbolTypes[sym.Kind]
+	}
+
+	return Invalid
+}
+
+func (c *checker) prefixType(expr *ast.PrefixExpression) Type {
+	typ := c.typeOf(expr.Right)
+
+	if expr.Operator == token.MINUS {
+		switch typ {
+		case Invalid, Integer, Float, RTime:
+			return typ
+		}
+
+		c.errorf(expr.Token.Pos, "invalid operation: operator %s not defined on %s", expr.Operator, typ)
+		return Invalid
+	}
+
+	if !isCondition(typ) {
+		c.errorf(expr.Token.Pos, "invalid operation: operator %s not defined on %s", expr.Operator, typ)
+	}
+	return Bool
+}
+
+func (c *checker) infixType(expr *ast.InfixExpression) Type {
+	left := c.typeOf(expr.Left)
+	right := c.typeOf(expr.Right)
+
+	return c.operatorType(expr.Token.Pos, expr.Operator, left, right)
+}
+
+// operatorType returns the type of the binary operator on the operands.
+// The operator is an infix operator or the operator of the compound assignment without "=" such as "<<".
+func (c *checker) operatorType(pos token.Position, operator string, left, right Type) Type {
+	switch operator {
+	case token.PLUS:
+		return c.plusType(pos, operator, left, right)
+	case token.MINUS:
+		return c.minusType(pos, operator, left, right)
+	case token.ASTERISK, token.SLASH:
+		return c.productType(pos, operator, left, right)
+	case token.PERCENT, "|", "&", "^", "<<", ">>", "ror", "rol":
+		return c.integerType(pos, operator, left, right)
+	case token.EQUAL, token.NOTEQUAL:
+		ok, _ := assignable(right, left)
+		if !ok && !(left.isNumeric() && right.isNumeric()) {
+			c.errorf(pos, "invalid operation: mismatched types %s %s %s", left, operator, right)
+		}
+	case token.LT, token.GT, token.LE, token.GE:
+		switch {
+		case left == Invalid || right == Invalid:
+		case left.isNumeric() && right.isNumeric():
+		case left == right && (left == Time || left == RTime):
+		default:
+			c.errorf(pos, "invalid operation: mismatched types %s %s %s", left, operator, right)
+		}
+	case token.MATCH, token.NOTMATCH:
+		switch {
+		case left == Invalid || right == Invalid:
+		case left == IP && right == ACL:
+		case left == String && right == String:
+		case left.isStringer() && right == String:
+			c.softErrorf(pos, "implicit conversion of %s to %s in %s", left, String, operator)
+		default:
+			c.errorf(pos, "invalid operation: mismatched types %s %s %s", left, operator, right)
+		}
+	case token.AND, token.OR:
+		if !isCondition(left) || !isCondition(right) {
+			c.errorf(pos, "invalid operation: operator %s not defined on %s and %s", operator, left, right)
+		}
+	}
+
+	return Bool
+}
+
+func (c *checker) minusType(pos token.Position, operator string, left, right Type) Type {
+	switch {
+	case left == Invalid || right == Invalid:
+		return Invalid
+	case left == Time && right == RTime:
+		return Time
+	case left == Time && right == Time, left == RTime && right == RTime:
+		return RTime
+	case left == Integer && right == Integer:
+		return Integer
+	case left.isNumeric() && right.isNumeric():
+		return Float
+	}
+
+	c.errorf(pos, "invalid operation: mismatched types %s %s %s", left, operator, right)
+	return Invalid
+}
+
+func (c *checker) productType(pos token.Position, operator string, left, right Type) Type {
+	switch {
+	case left == Invalid || right == Invalid:
+		return Invalid
+	case left == Integer && right == Integer:
+		return Integer
+	case left.isNumeric() && right.isNumeric():
+		return Float
+	case left == RTime && right.isNumeric():
+		return RTime
+	case operator == token.ASTERISK && left.isNumeric() && right == RTime:
+		return RTime
+	}
+
+	c.errorf(pos, "invalid operation: mismatched types %s %s %s", left, operator, right)
+	return Invalid
+}
+
+// integerType returns the type of the operators which are only defined on INTEGER such as % and <<
+func (c *checker) integerType(pos token.Position, operator string, left, right Type) Type {
+	switch {
+	case left == Invalid || right == Invalid:
+		return Invalid
+	case left == Integer && right == Integer:
+		return Integer
+	}
+
+	c.errorf(pos, "invalid operation: operator %s not defined on %s and %s", operator, left, right)
+	return Invalid
+}
+
+func (c *checker) plusType(pos token.Position, operator string, left, right Type) Type {
+	switch {
+	case left == Invalid || right == Invalid:
+		if left == String || right == String {
+			return String
+		}
+		return Invalid
+	case left == Time && right == RTime, left == RTime && right == Time:
+		return Time
+	case left == RTime && right == RTime:
+		return RTime
+	case left == String && right == String:
+		return String
+	case left == String && right.isStringer(), right == String && left.isStringer():
+		nonString := left
+		if left == String {
+			nonString = right
+		}
+		c.softErrorf(pos, "implicit conversion of %s to %s in %s", nonString, String, operator)
+		return String
+	case left == Integer && right == Integer:
+		return Integer
+	case left.isNumeric() && right.isNumeric():
+		return Float
+	}
+
+	c.errorf(pos, "invalid operation: mismatched types %s %s %s", left, operator, right)
+	return Invalid
+}
+
+func (c *checker) callType(expr *ast.CallExpression) Type {
+	args := make([]Type, len(expr.Arguments))
+	for i, arg := range expr.Arguments {
+		args[i] = c.typeOf(arg)
+	}
+
+	ident, ok := expr.Function.(*ast.Identifier)
+	if !ok {
+		return Invalid
+	}
+
+	fn, ok := LookupFunction(ident.Value)
+	if !ok {
+		return Invalid
+	}
+
+	if min := len(fn.Params) - fn.Optional; len(args) < min || len(args) > len(fn.Params) {
+		c.errorf(ident.Token.Pos, "wrong number of arguments in call to %s, got:%d, want:%d", fn.Name, len(args), len(fn.Params))
+		return fn.Return
+	}
+
+	for i, arg := range expr.Arguments {
+		c.assign(arg, args[i], fn.Params[i], fmt.Sprintf("argument %d to %s", i+1, fn.Name))
+	}
+
+	return fn.Return
+}
+
+// position returns the position where the expression starts
+func position(expr ast.Expression) token.Position {
+	switch v := expr.(type) {
+	case *ast.InfixExpression:
+		return position(v.Left)
+	case *ast.CallExpression:
+		return position(v.Function)
+	case *ast.Identifier:
+		return v.Token.Pos
+	case *ast.StringLiteral:
+		return v.Token.Pos
+	case *ast.IntegerLiteral:
+		return v.Token.Pos
+	case *ast.BooleanLiteral:
+		return v.Token.Pos
+	case *ast.RTimeLiteral:
+		return v.Token.Pos
+	case *ast.CIDRLiteral:
+		return v.Token.Pos
+	case *ast.PercentageLiteral:
+		return v.Token.Pos
+	case *ast.PrefixExpression:
+		return v.Token.Pos
+	case *ast.IfExpression:
+		return v.Token.Pos
+	case *ast.BlockExpression:
+		return v.Token.Pos
+	}
+
+	return token.Position{}
+}
@@ -0,0 +1,189 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected []string
+		soft     []bool
+	}{
+		"with valid assignments": {`sub vcl_fetch {
+	set beresp.ttl = 10s;
+	set beresp.status = 200;
+	set beresp.http.X-Foo = "bar";
+	set beresp.cacheable = true;
+}`, []string{}, []bool{}},
+		"with implicit conversion": {`sub vcl_recv {
+	set req.http.X-Restarts = req.restarts;
+}`, []string{"2:28: implicit conversion of INTEGER to STRING in assignment to req.http.X-Restarts"}, []bool{true}},
+		"with mismatched assignment": {`sub vcl_fetch {
+	set beresp.ttl = "10s";
+}`, []string{"2:19: cannot use STRING as RTIME in assignment to beresp.ttl"}, []bool{false}},
+		"with read-only variable": {`sub vcl_recv {
+	set client.ip = "127.0.0.1";
+	unset req.xid;
+}`, []string{"2:6: cannot assign to read-only variable client.ip", "3:8: cannot unset read-only variable req.xid"}, []bool{false, false}},
+		"with mismatched operands": {`sub vcl_recv {
+	set req.http.X-Foo = 5 + true;
+	if (req.restarts == "1") {}
+}`, []string{"2:25: invalid operation: mismatched types INTEGER + BOOL", "3:19: invalid operation: mismatched types INTEGER == STRING"}, []bool{false, false}},
+		"with string concatenation": {`sub vcl_recv {
+	set req.http.X-Foo = "restarts:" + req.restarts;
+}`, []string{"2:35: implicit conversion of INTEGER to STRING in +"}, []bool{true}},
+		"with acl match": {`acl internal {
+	"localhost";
+}
+
+sub vcl_recv {
+	if (client.ip ~ internal) {}
+	if (req.restarts ~ internal) {}
+}`, []string{"7:19: invalid operation: mismatched types INTEGER ~ ACL"}, []bool{false}},
+		"with non-boolean condition": {`sub vcl_recv {
+	if (req.restarts) {}
+}`, []string{"2:6: non-boolean condition INTEGER in if"}, []bool{false}},
+		"with function arguments": {`sub vcl_recv {
+	set req.url = regsub(req.url, "^/foo");
+	set req.http.X-Len = std.strlen(req.restarts);
+	set req.http.X-Foo = std.tolower(req.http.X-Foo);
+}`, []string{"2:16: wrong number of arguments in call to regsub, got:2, want:3", "3:34: implicit conversion of INTEGER to STRING in argument 1 to std.strlen", "3:23: implicit conversion of INTEGER to STRING in assignment to req.http.X-Len"}, []bool{false, true, true}},
+		"with function returns": {`sub vcl_fetch {
+	set beresp.ttl = std.duration(beresp.http.X-Ttl, 10s);
+	set beresp.status = std.atoi(beresp.http.X-Status);
+}`, []string{}, []bool{}},
+		"with relational operators": {`sub vcl_fetch {
+	if (beresp.status >= 500) {}
+	if (beresp.ttl < 10s) {}
+	if (beresp.ttl > 100) {}
+}`, []string{"4:17: invalid operation: mismatched types RTIME > INTEGER"}, []bool{false}},
+		"with arithmetic operators": {`sub vcl_fetch {
+	set beresp.ttl = -1s;
+	set beresp.ttl = beresp.ttl * 2 - 10s;
+	set beresp.status = beresp.status % 100 + 1;
+	set beresp.status = -beresp.status / 2;
+}`, []string{}, []bool{}},
+		"with mismatched arithmetic operands": {`sub vcl_fetch {
+	set beresp.ttl = 10s % 2;
+	set beresp.status = 2 / 10s;
+	set beresp.status = -"a";
+}`, []string{"2:23: invalid operation: operator % not defined on RTIME and INTEGER", "3:24: invalid operation: mismatched types INTEGER / RTIME", "4:22: invalid operation: operator - not defined on STRING"}, []bool{false, false, false}},
+		"with compound assignments": {`sub vcl_fetch {
+	set beresp.ttl += 10s;
+	set beresp.status *= 2;
+	set beresp.status <<= 1;
+	set beresp.http.X-Foo += "bar";
+	set beresp.cacheable &&= false;
+	set beresp.ttl <<= 1;
+	set beresp.status -= "1";
+}`, []string{"7:6: invalid operation: operator << not defined on RTIME and INTEGER", "8:6: invalid operation: mismatched types INTEGER - STRING"}, []bool{false, false}},
+		"with cidr and percentage literals": {`sub vcl_recv {
+	if (client.ip == "10.0.0.0"/8) {}
+	set req.http.X-Ratio = 50%;
+}`, []string{"2:16: invalid operation: mismatched types IP == CIDR", "3:25: implicit conversion of FLOAT to STRING in assignment to req.http.X-Ratio"}, []bool{false, true}},
+		"with unknown identifiers": {`sub vcl_recv {
+	set req.http.X-Foo = custom.value + 1;
+	set var.foo = unknown(1);
+}`, []string{}, []bool{}},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			l := lexer.NewLexer(tc.input)
+			p := parser.NewParser(l)
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("parser has errors, errs:%v", p.Errors())
+			}
+
+			errs := Check(program)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("check errors length failed, got:%d, want:%d, errs:%v", len(errs), len(tc.expected), errs)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.expected[i] {
+					t.Fatalf("error[%d] failed, got:%s, want:%s", i, err.Error(), tc.expected[i])
+				}
+
+				if soft := err.(*Error).Soft; soft != tc.soft[i] {
+					t.Fatalf("error[%d] soft failed, got:%v, want:%v", i, soft, tc.soft[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLookupVariable(t *testing.T) {
+	testCases := map[string]struct {
+		name     string
+		found    bool
+		typ      Type
+		readOnly bool
+	}{
+		"with variable":           {"beresp.ttl", true, RTime, false},
+		"with read-only variable": {"client.ip", true, IP, true},
+		"with header":             {"req.http.Host", true, String, false},
+		"with family prefix only": {"req.http.", false, Invalid, false},
+		"with unknown variable":   {"req.unknown", false, Invalid, false},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			v, ok := LookupVariable(tc.name)
+			if ok != tc.found {
+				t.Fatalf("found failed, got:%v, want:%v", ok, tc.found)
+			}
+
+			if !ok {
+				return
+			}
+
+			if v.Type != tc.typ {
+				t.Fatalf("type failed, got:%s, want:%s", v.Type, tc.typ)
+			}
+
+			if v.ReadOnly != tc.readOnly {
+				t.Fatalf("read-only failed, got:%v, want:%v", v.ReadOnly, tc.readOnly)
+			}
+		})
+	}
+}
+
+func TestLookupFunction(t *testing.T) {
+	testCases := map[string]struct {
+		name   string
+		found  bool
+		ret    Type
+		params int
+	}{
+		"with function":         {"regsub", true, String, 3},
+		"with module function":  {"table.lookup", true, String, 3},
+		"with unknown function": {"std.unknown", false, Invalid, 0},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			f, ok := LookupFunction(tc.name)
+			if ok != tc.found {
+				t.Fatalf("found failed, got:%v, want:%v", ok, tc.found)
+			}
+
+			if !ok {
+				return
+			}
+
+			if f.Return != tc.ret {
+				t.Fatalf("return type failed, got:%s, want:%s", f.Return, tc.ret)
+			}
+
+			if len(f.Params) != tc.params {
+				t.Fatalf("params length failed, got:%d, want:%d", len(f.Params), tc.params)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package types
+
+import "sort"
+
+// Function is a signature of the built-in function
+type Function struct {
+	Name     string
+	Params   []Type
+	Optional int // number of the trailing parameters which can be omitted
+	Return   Type
+	Doc      string
+}
+
+// functions is the catalogue of the built-in functions by name
+var functions = map[string]*Function{}
+
+func init() {
+	for _, f := range []*Function{
+		{"regsub", []Type{String, String, String}, 0, String, "Replaces the first match of the regex with the replacement."},
+		{"regsuball", []Type{String, String, String}, 0, String, "Replaces all matches of the regex with the replacement."},
+		{"substr", []Type{String, Integer, Integer}, 1, String, "Returns the substring from the offset with the length."},
+		{"urlencode", []Type{String}, 0, String, "Encodes the string for the URL."},
+		{"urldecode", []Type{String}, 0, String, "Decodes the URL encoded string."},
+		{"randombool", []Type{Integer, Integer}, 0, Bool, "Returns true with the probability of numerator / denominator."},
+		{"randomint", []Type{Integer, Integer}, 0, Integer, "Returns a random integer between from and to."},
+		{"strftime", []Type{String, Time}, 0, String, "Formats the time by the format."},
+
+		{"std.tolower", []Type{String}, 0, String, "Converts the string to lower case."},
+		{"std.toupper", []Type{String}, 0, String, "Converts the string to upper case."},
+		{"std.strlen", []Type{String}, 0, Integer, "Returns the length of the string."},
+		{"std.atoi", []Type{String}, 0, Integer, "Converts the string to an integer."},
+		{"std.integer", []Type{String, Integer}, 0, Integer, "Converts the string to an integer or returns the fallback."},
+		{"std.duration", []Type{String, RTime}, 0, RTime, "Converts the string to a relative time or returns the fallback."},
+		{"std.ip", []Type{String, String}, 0, IP, "Converts the string to an IP address or returns the fallback."},
+		{"std.str2ip", []Type{String, String}, 0, IP, "Converts the string to an IP address or returns the fallback."},
+		{"std.time", []Type{String, Time}, 0, Time, "Converts the string to a time or returns the fallback."},
+		{"std.log", []Type{String}, 0, Void, "Logs the string."},
+
+		{"table.lookup", []Type{Table, String, String}, 1, String, "Returns the value of the key in the table or the default."},
+		{"table.lookup_integer", []Type{Table, String, Integer}, 0, Integer, "Returns the integer value of the key in the table or the default."},
+		{"table.lookup_bool", []Type{Table, String, Bool}, 0, Bool, "Returns the boolean value of the key in the table or the default."},
+		{"table.lookup_rtime", []Type{Table, String, RTime}, 0, RTime, "Returns the relative time value of the key in the table or the default."},
+		{"table.lookup_ip", []Type{Table, String, IP}, 0, IP, "Returns the IP address value of the key in the table or the default."},
+		{"table.contains", []Type{Table, String}, 0, Bool, "Returns whether the table contains the key."},
+
+		{"digest.hash_md5", []Type{String}, 0, String, "Returns the MD5 hash of the string."},
+		{"digest.hash_sha1", []Type{String}, 0, String, "Returns the SHA-1 hash of the string."},
+		{"digest.hash_sha256", []Type{String}, 0, String, "Returns the SHA-256 hash of the string."},
+		{"digest.base64", []Type{String}, 0, String, "Encodes the string by base64."},
+		{"digest.base64_decode", []Type{String}, 0, String, "Decodes the base64 encoded string."},
+
+		{"querystring.get", []Type{String, String}, 0, String, "Returns the value of the query parameter."},
+		{"querystring.remove", []Type{String}, 0, String, "Removes the query string from the URL."},
+		{"querystring.sort", []Type{String}, 0, String, "Sorts the query parameters of the URL."},
+		{"querystring.filter", []Type{String, String}, 0, String, "Removes the query parameters from the URL."},
+		{"querystring.filter_except", []Type{String, String}, 0, String, "Removes the query parameters from the URL except the listed ones."},
+		{"boltsort.sort", []Type{String}, 0, String, "Sorts the query parameters of the URL."},
+
+		{"time.add", []Type{Time, RTime}, 0, Time, "Adds the relative time to the time."},
+		{"time.sub", []Type{Time, RTime}, 0, Time, "Subtracts the relative time from the time."},
+		{"time.is_after", []Type{Time, Time}, 0, Bool, "Returns whether the first time is after the second time."},
+
+		{"addr.is_ipv4", []Type{IP}, 0, Bool, "Returns whether the address is IPv4."},
+		{"addr.is_ipv6", []Type{IP}, 0, Bool, "Returns whether the address is IPv6."},
+		{"json.escape", []Type{String}, 0, String, "Escapes the string for JSON."},
+
+		{"ratelimit.check_rate", []Type{String, Ratecounter, Integer, Integer, Integer, Penaltybox, RTime}, 0, Bool, "Increments the rate counter and returns whether the client exceeds the limit."},
+		{"ratelimit.check_rates", []Type{String, Ratecounter, Integer, Integer, Integer, Ratecounter, Integer, Integer, Integer, Penaltybox, RTime}, 0, Bool, "Increments the rate counters and returns whether the client exceeds either limit."},
+		{"ratelimit.ratecounter_increment", []Type{Ratecounter, String, Integer}, 0, Integer, "Increments the rate counter of the entry."},
+		{"ratelimit.penaltybox_add", []Type{Penaltybox, String, RTime}, 0, Void, "Adds the entry to the penalty box for the duration."},
+		{"ratelimit.penaltybox_has", []Type{Penaltybox, String}, 0, Bool, "Returns whether the entry is in the penalty box."},
+	} {
+		functions[f.Name] = f
+	}
+}
+
+// LookupFunction returns the built-in function by the name
+func LookupFunction(name string) (*Function, bool) {
+	f, ok := functions[name]
+	return f, ok
+}
+
+// Functions returns all built-in functions sorted by the name
+func Functions() []*Function {
+	ret := make([]*Function, 0, len(functions))
+	for _, f := range functions {
+		ret = append(ret, f)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret
+}
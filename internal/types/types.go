@@ -0,0 +1,77 @@
+package types
+
+// Type is a type of VCL expressions
+type Type int
+
+const (
+	Invalid Type = iota // unknown type which is not checked
+	String
+	Bool
+	Integer
+	Float
+	Time
+	RTime
+	IP
+	CIDR
+	Backend
+	ACL
+	Table
+	Penaltybox
+	Ratecounter
+	Void
+)
+
+var typeNames = map[Type]string{
+	Invalid:     "INVALID",
+	String:      "STRING",
+	Bool:        "BOOL",
+	Integer:     "INTEGER",
+	Float:       "FLOAT",
+	Time:        "TIME",
+	RTime:       "RTIME",
+	IP:          "IP",
+	CIDR:        "CIDR",
+	Backend:     "BACKEND",
+	ACL:         "ACL",
+	Table:       "TABLE",
+	Penaltybox:  "PENALTYBOX",
+	Ratecounter: "RATECOUNTER",
+	Void:        "VOID",
+}
+
+// String returns the name of the type as written in VCL such as STRING
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// isNumeric reports whether the type is a number
+func (t Type) isNumeric() bool {
+	return t == Integer || t == Float
+}
+
+// isStringer reports whether the type can be converted to STRING implicitly
+func (t Type) isStringer() bool {
+	switch t {
+	case String, Bool, Integer, Float, Time, RTime, IP, Backend:
+		return true
+	}
+
+	return false
+}
+
+// assignable reports whether the value of the type from can be used as the type to.
+// The implicit is true when the value is converted implicitly.
+func assignable(from, to Type) (ok bool, implicit bool) {
+	switch {
+	case from == Invalid || to == Invalid:
+		return true, false
+	case from == to:
+		return true, false
+	case to == String && from.isStringer():
+		return true, true
+	case to == Float && from == Integer:
+		return true, true
+	}
+
+	return false, false
+}
@@ -0,0 +1,171 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// Variable is a built-in variable of VCL
+type Variable struct {
+	Name     string
+	Type     Type
+	ReadOnly bool
+	Doc      string
+}
+
+// variables is the catalogue of the built-in variables by name
+var variables = map[string]*Variable{}
+
+// wildcardVariables are the families of the variables such as req.http.* by prefix
+var wildcardVariables = map[string]*Variable{}
+
+func init() {
+	for _, v := range []*Variable{
+		{"req.url", String, false, "The URL of the request including the query string."},
+		{"req.url.path", String, true, "The path of the request URL without the query string."},
+		{"req.url.qs", String, true, "The query string of the request URL."},
+		{"req.url.ext", String, true, "The file extension of the request URL."},
+		{"req.url.basename", String, true, "The file name of the request URL."},
+		{"req.url.dirname", String, true, "The directory of the request URL."},
+		{"req.method", String, false, "The HTTP method of the request."},
+		{"req.request", String, false, "The HTTP method of the request."},
+		{"req.proto", String, true, "The HTTP protocol version of the request."},
+		{"req.backend", Backend, false, "The backend to fetch the request from."},
+		{"req.backend.healthy", Bool, true, "Whether the backend of the request is healthy."},
+		{"req.backend_hint", Backend, false, "The backend hint of the request."},
+		{"req.restarts", Integer, true, "The number of times the request has been restarted."},
+		{"req.hash", String, false, "The hash key of the request."},
+		{"req.hash_always_miss", Bool, false, "Forces a cache miss for the request."},
+		{"req.hash_ignore_busy", Bool, false, "Ignores any busy object during the cache lookup."},
+		{"req.esi", Bool, false, "Whether ESI is processed for the request."},
+		{"req.grace", RTime, false, "The grace period accepted for the request."},
+		{"req.xid", String, true, "The unique identifier of the request."},
+		{"req.is_ssl", Bool, true, "Whether the request is received over TLS."},
+		{"req.is_ipv6", Bool, true, "Whether the request is received over IPv6."},
+		{"req.topurl", String, true, "The URL of the top level request of ESI."},
+		{"req.body", String, true, "The body of the request."},
+		{"req.digest", String, true, "The digest of the hash key of the request."},
+		{"req.service_id", String, true, "The identifier of the service."},
+		{"req.max_stale_if_error", RTime, false, "The maximum stale time served when the backend has an error."},
+		{"req.max_stale_while_revalidate", RTime, false, "The maximum stale time served while revalidating."},
+
+		{"bereq.url", String, false, "The URL of the backend request."},
+		{"bereq.method", String, false, "The HTTP method of the backend request."},
+		{"bereq.request", String, false, "The HTTP method of the backend request."},
+		{"bereq.proto", String, true, "The HTTP protocol version of the backend request."},
+		{"bereq.backend", Backend, false, "The backend of the backend request."},
+		{"bereq.connect_timeout", RTime, false, "The timeout to connect to the backend."},
+		{"bereq.first_byte_timeout", RTime, false, "The timeout to receive the first byte from the backend."},
+		{"bereq.between_bytes_timeout", RTime, false, "The timeout between bytes received from the backend."},
+		{"bereq.retries", Integer, true, "The number of retries of the backend request."},
+
+		{"beresp.status", Integer, false, "The HTTP status code of the backend response."},
+		{"beresp.response", String, false, "The HTTP status message of the backend response."},
+		{"beresp.reason", String, false, "The HTTP status message of the backend response."},
+		{"beresp.proto", String, true, "The HTTP protocol version of the backend response."},
+		{"beresp.ttl", RTime, false, "The time to live of the object in the cache."},
+		{"beresp.grace", RTime, false, "The grace period of the object."},
+		{"beresp.keep", RTime, false, "The period to keep the object for conditional requests."},
+		{"beresp.stale_if_error", RTime, false, "The period to serve the stale object when the backend has an error."},
+		{"beresp.stale_while_revalidate", RTime, false, "The period to serve the stale object while revalidating."},
+		{"beresp.cacheable", Bool, false, "Whether the backend response is cacheable."},
+		{"beresp.uncacheable", Bool, false, "Whether the object is marked as hit-for-pass."},
+		{"beresp.do_esi", Bool, false, "Whether ESI is processed for the backend response."},
+		{"beresp.do_stream", Bool, false, "Whether the backend response is streamed."},
+		{"beresp.do_gzip", Bool, false, "Whether the backend response is compressed by gzip."},
+		{"beresp.gzip", Bool, false, "Whether the backend response is compressed by gzip."},
+		{"beresp.brotli", Bool, false, "Whether the backend response is compressed by brotli."},
+		{"beresp.backend.name", String, true, "The name of the backend which sent the response."},
+		{"beresp.backend.ip", IP, true, "The IP address of the backend which sent the response."},
+		{"beresp.backend.port", Integer, true, "The port of the backend which sent the response."},
+
+		{"obj.status", Integer, false, "The HTTP status code of the cached object."},
+		{"obj.response", String, false, "The HTTP status message of the cached object."},
+		{"obj.reason", String, true, "The HTTP status message of the cached object."},
+		{"obj.proto", String, false, "The HTTP protocol version of the cached object."},
+		{"obj.ttl", RTime, false, "The remaining time to live of the cached object."},
+		{"obj.grace", RTime, false, "The grace period of the cached object."},
+		{"obj.hits", Integer, true, "The number of cache hits of the object."},
+		{"obj.lastuse", RTime, true, "The time since the object was last used."},
+		{"obj.age", RTime, true, "The age of the cached object."},
+		{"obj.cacheable", Bool, true, "Whether the object is cacheable."},
+		{"obj.stale_if_error", RTime, false, "The period to serve the stale object when the backend has an error."},
+		{"obj.stale_while_revalidate", RTime, false, "The period to serve the stale object while revalidating."},
+
+		{"resp.status", Integer, false, "The HTTP status code of the response."},
+		{"resp.response", String, false, "The HTTP status message of the response."},
+		{"resp.reason", String, false, "The HTTP status message of the response."},
+		{"resp.proto", String, false, "The HTTP protocol version of the response."},
+		{"resp.is_locally_generated", Bool, true, "Whether the response is generated by the edge."},
+		{"resp.stale", Bool, true, "Whether the response is stale."},
+
+		{"client.ip", IP, true, "The IP address of the client."},
+		{"client.port", Integer, true, "The port of the client."},
+		{"client.identity", String, false, "The identity of the client used by the client director."},
+		{"client.requests", Integer, true, "The number of requests on the connection of the client."},
+		{"client.as.number", Integer, true, "The autonomous system number of the client."},
+		{"client.geo.latitude", Float, true, "The latitude of the client."},
+		{"client.geo.longitude", Float, true, "The longitude of the client."},
+
+		{"server.ip", IP, true, "The IP address of the server."},
+		{"server.port", Integer, true, "The port of the server."},
+		{"server.hostname", String, true, "The host name of the server."},
+		{"server.identity", String, true, "The identity of the server."},
+		{"server.region", String, true, "The region of the server."},
+		{"server.datacenter", String, true, "The datacenter of the server."},
+
+		{"now", Time, true, "The current time."},
+		{"now.sec", String, true, "The current time in seconds since the epoch."},
+		{"time.start", Time, true, "The time when the request started."},
+		{"time.elapsed", RTime, true, "The time since the request started."},
+
+		{"fastly_info.state", String, true, "The state of the request in the edge."},
+		{"fastly.error", String, true, "The error of the last function call."},
+		{"esi.allow_inside_cdata", Bool, false, "Whether ESI is processed inside CDATA."},
+	} {
+		variables[v.Name] = v
+	}
+
+	for _, v := range []*Variable{
+		{"req.http.", String, false, "The header of the request."},
+		{"bereq.http.", String, false, "The header of the backend request."},
+		{"beresp.http.", String, false, "The header of the backend response."},
+		{"obj.http.", String, false, "The header of the cached object."},
+		{"resp.http.", String, false, "The header of the response."},
+		{"client.geo.", String, true, "The geolocation of the client."},
+		{"client.as.", String, true, "The autonomous system of the client."},
+		{"geoip.", String, true, "The geolocation of the client."},
+	} {
+		wildcardVariables[v.Name] = v
+	}
+}
+
+// LookupVariable returns the built-in variable by the name.
+// Headers like req.http.Host are resolved by their family such as req.http.*.
+func LookupVariable(name string) (*Variable, bool) {
+	if v, ok := variables[name]; ok {
+		return v, true
+	}
+
+	for prefix, v := range wildcardVariables {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// Variables returns all built-in variables sorted by the name except the families like req.http.*
+func Variables() []*Variable {
+	ret := make([]*Variable, 0, len(variables))
+	for _, v := range variables {
+		ret = append(ret, v)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret
+}
@@ -0,0 +1,59 @@
+package vcldiff
+
+import "github.com/KeisukeYamashita/go-vcl/internal/ast"
+
+// stripStatements returns the statements without the comments.
+// The nodes which have the comments are copied so that the program is not modified.
+func stripStatements(stmts []ast.Statement) []ast.Statement {
+	stripped := make([]ast.Statement, 0, len(stmts))
+	for _, stmt := range stmts {
+		switch v := stmt.(type) {
+		case *ast.CommentStatement:
+			continue
+		case *ast.ExpressionStatement:
+			c := *v
+			c.Expression = stripExpression(v.Expression)
+			stmt = &c
+		case *ast.AssignStatement:
+			c := *v
+			c.Value = stripExpression(v.Value)
+			stmt = &c
+		case *ast.AssignFieldStatement:
+			c := *v
+			c.Value = stripExpression(v.Value)
+			stmt = &c
+		case *ast.BlockStatement:
+			stmt = stripBlock(v)
+		}
+
+		stripped = append(stripped, stmt)
+	}
+
+	return stripped
+}
+
+func stripExpression(expr ast.Expression) ast.Expression {
+	switch v := expr.(type) {
+	case *ast.IfExpression:
+		c := *v
+		c.Consequence = stripBlock(v.Consequence)
+		c.Alternative = stripBlock(v.Alternative)
+		return &c
+	case *ast.BlockExpression:
+		c := *v
+		c.Blocks = stripBlock(v.Blocks)
+		return &c
+	}
+
+	return expr
+}
+
+func stripBlock(block *ast.BlockStatement) *ast.BlockStatement {
+	if block == nil {
+		return nil
+	}
+
+	c := *block
+	c.Statements = stripStatements(block.Statements)
+	return &c
+}
@@ -0,0 +1,385 @@
+package vcldiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/printer"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Kind is the kind of the change
+type Kind string
+
+const (
+	// Added means the declaration or the member only exists in the new program
+	Added Kind = "added"
+
+	// Removed means the declaration or the member only exists in the old program
+	Removed Kind = "removed"
+
+	// Changed means the declaration or the member exists in both programs with the different values
+	Changed Kind = "changed"
+)
+
+var kindMarks = map[Kind]string{
+	Added:   "+",
+	Removed: "-",
+	Changed: "~",
+}
+
+// Change is a difference between the programs.
+// Member is empty when the declaration itself is added, removed or changed such as the type of the table.
+// Old and New are the statements in the canonical format on a line, and empty if they do not exist.
+type Change struct {
+	Kind        Kind
+	Declaration string // such as "backend F_origin"
+	Member      string // such as ".host", "\"/old\"" or the statement of the subroutine
+	Old         string
+	New         string
+	OldPos      token.Position
+	NewPos      token.Position
+}
+
+// String returns the change such as `~ backend F_origin: .port = "80"; -> .port = "443";`
+func (c *Change) String() string {
+	var b strings.Builder
+	b.WriteString(kindMarks[c.Kind])
+	b.WriteString(" ")
+	b.WriteString(c.Declaration)
+
+	switch {
+	case c.Member == "" && c.Kind != Changed:
+		return b.String()
+	case c.Kind == Added:
+		b.WriteString(": " + c.New)
+	case c.Kind == Removed:
+		b.WriteString(": " + c.Old)
+	default:
+		b.WriteString(": " + c.Old + " -> " + c.New)
+	}
+
+	return b.String()
+}
+
+// declaration is a top-level declaration with the members in the canonical format
+type declaration struct {
+	key    string // keyword and name such as "backend F_origin"
+	header string // keyword and all labels such as "director my_dir random"
+	pos    token.Position
+
+	// members are the statements of the body.
+	// Members with the names such as `.host = "example.com";` are compared by the name,
+	// and the others are compared as a set or, for the subroutines, as a sequence.
+	members []*member
+}
+
+type member struct {
+	name string
+	text string
+	pos  token.Position
+}
+
+// Diff returns the changes from the old program to the new one.
+// Declarations are matched by the keyword and the name, and the formatting and the comments are ignored.
+// Subroutines are compared statement by statement, and the other declarations by the properties, the entries and the table keys.
+func Diff(old, new *ast.Program) ([]*Change, error) {
+	olds, err := declarations(old)
+	if err != nil {
+		return nil, err
+	}
+
+	news, err := declarations(new)
+	if err != nil {
+		return nil, err
+	}
+
+	newByKey := map[string]*declaration{}
+	for _, d := range news {
+		newByKey[d.key] = d
+	}
+
+	oldByKey := map[string]*declaration{}
+	changes := []*Change{}
+	for _, o := range olds {
+		oldByKey[o.key] = o
+		n, ok := newByKey[o.key]
+		if !ok {
+			changes = append(changes, &Change{Kind: Removed, Declaration: o.key, Old: o.header, OldPos: o.pos})
+			continue
+		}
+
+		if o.header != n.header {
+			changes = append(changes, &Change{Kind: Changed, Declaration: o.key, Old: o.header, New: n.header, OldPos: o.pos, NewPos: n.pos})
+		}
+
+		if strings.HasPrefix(o.key, "sub ") {
+			changes = append(changes, diffSequence(o, n)...)
+		} else {
+			changes = append(changes, diffMembers(o, n)...)
+		}
+	}
+
+	for _, n := range news {
+		if _, ok := oldByKey[n.key]; !ok {
+			changes = append(changes, &Change{Kind: Added, Declaration: n.key, New: n.header, NewPos: n.pos})
+		}
+	}
+
+	return changes, nil
+}
+
+// declarations returns the declarations of the program in the order.
+// Declarations with the same name such as `sub vcl_recv` which is split into multiple blocks are merged.
+func declarations(program *ast.Program) ([]*declaration, error) {
+	decls := []*declaration{}
+	byKey := map[string]*declaration{}
+	for _, stmt := range program.Statements {
+		es, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+
+		expr, ok := es.Expression.(*ast.BlockExpression)
+		if !ok || len(expr.Labels) == 0 {
+			continue
+		}
+
+		key := expr.Token.Literal + " " + expr.Labels[0]
+		d, ok := byKey[key]
+		if !ok {
+			d = &declaration{
+				key:    key,
+				header: strings.Join(append([]string{expr.Token.Literal}, expr.Labels...), " "),
+				pos:    expr.Token.Pos,
+			}
+			byKey[key] = d
+			decls = append(decls, d)
+		}
+
+		if expr.Blocks == nil {
+			continue
+		}
+
+		members, err := members(expr.Blocks.Statements)
+		if err != nil {
+			return nil, err
+		}
+
+		d.members = append(d.members, members...)
+	}
+
+	return decls, nil
+}
+
+// members returns the statements in the canonical format without the comments.
+// Actions such as `error 403 "Forbidden";` which are parsed as the expressions on the same line are kept together.
+func members(stmts []ast.Statement) ([]*member, error) {
+	stmts = stripStatements(stmts)
+	members := []*member{}
+	for i := 0; i < len(stmts); {
+		j := i + 1
+		for isAction(stmts[i]) && j < len(stmts) && sameLine(stmts[j-1], stmts[j]) {
+			j++
+		}
+
+		text, err := printer.Print(&ast.Program{Statements: stmts[i:j]})
+		if err != nil {
+			return nil, err
+		}
+
+		m := &member{text: oneLine(text), pos: startPos(stmts[i])}
+		switch v := stmts[i].(type) {
+		case *ast.AssignStatement:
+			m.name = v.Name.Value
+		case *ast.AssignFieldStatement:
+			m.name = fmt.Sprintf("%q", v.Name.Value)
+		}
+
+		members = append(members, m)
+		i = j
+	}
+
+	return members, nil
+}
+
+// isAction reports whether the statement is the name of the action such as `error`
+func isAction(stmt ast.Statement) bool {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+
+	_, ok = es.Expression.(*ast.Identifier)
+	return ok
+}
+
+// sameLine reports whether the statements are the expressions on the same line such as `error 403`
+func sameLine(prev, next ast.Statement) bool {
+	p, ok := prev.(*ast.ExpressionStatement)
+	if !ok || !isSimple(p.Expression) {
+		return false
+	}
+
+	n, ok := next.(*ast.ExpressionStatement)
+	if !ok || !isSimple(n.Expression) {
+		return false
+	}
+
+	return p.Token.Pos.IsValid() && p.Token.Pos.Line == n.Token.Pos.Line
+}
+
+func isSimple(expr ast.Expression) bool {
+	switch expr.(type) {
+	case *ast.BlockExpression, *ast.IfExpression:
+		return false
+	}
+
+	return true
+}
+
+// oneLine joins the lines of the printed statement, which ignores the blank lines and the objects written on a line
+func oneLine(text string) string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, " ")
+}
+
+func startPos(stmt ast.Statement) token.Position {
+	switch v := stmt.(type) {
+	case *ast.AssignStatement:
+		return v.Name.Token.Pos
+	case *ast.AssignFieldStatement:
+		return v.Name.Token.Pos
+	case *ast.SetStatement:
+		return v.Token.Pos
+	case *ast.UnsetStatement:
+		return v.Token.Pos
+	case *ast.ReturnStatement:
+		return v.Token.Pos
+	case *ast.CallStatement:
+		return v.Token.Pos
+	case *ast.ExpressionStatement:
+		return v.Token.Pos
+	}
+
+	return token.Position{}
+}
+
+// diffMembers compares the named members by the name and the others as a set
+func diffMembers(o, n *declaration) []*Change {
+	changes := []*Change{}
+	newByName := map[string]*member{}
+	newTexts := map[string][]*member{}
+	for _, m := range n.members {
+		if m.name != "" {
+			newByName[m.name] = m
+		} else {
+			newTexts[m.text] = append(newTexts[m.text], m)
+		}
+	}
+
+	oldNames := map[string]bool{}
+	for _, m := range o.members {
+		if m.name == "" {
+			if ms := newTexts[m.text]; len(ms) > 0 {
+				newTexts[m.text] = ms[1:]
+				continue
+			}
+
+			changes = append(changes, &Change{Kind: Removed, Declaration: o.key, Member: m.text, Old: m.text, OldPos: m.pos})
+			continue
+		}
+
+		oldNames[m.name] = true
+		nm, ok := newByName[m.name]
+		switch {
+		case !ok:
+			changes = append(changes, &Change{Kind: Removed, Declaration: o.key, Member: m.name, Old: m.text, OldPos: m.pos})
+		case nm.text != m.text:
+			changes = append(changes, &Change{Kind: Changed, Declaration: o.key, Member: m.name, Old: m.text, New: nm.text, OldPos: m.pos, NewPos: nm.pos})
+		}
+	}
+
+	for _, m := range n.members {
+		if m.name != "" {
+			if !oldNames[m.name] {
+				changes = append(changes, &Change{Kind: Added, Declaration: n.key, Member: m.name, New: m.text, NewPos: m.pos})
+			}
+			continue
+		}
+
+		// the entries which are not matched are left in the set
+		if ms := newTexts[m.text]; len(ms) > 0 && ms[0] == m {
+			newTexts[m.text] = ms[1:]
+			changes = append(changes, &Change{Kind: Added, Declaration: n.key, Member: m.text, New: m.text, NewPos: m.pos})
+		}
+	}
+
+	return changes
+}
+
+// diffSequence compares the statements by the longest common subsequence.
+// A removed statement followed by an added one is reported as a change.
+func diffSequence(o, n *declaration) []*Change {
+	a, b := o.members, n.members
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].text == b[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	changes := []*Change{}
+	var removed, added []*member
+	flush := func() {
+		for k := 0; k < len(removed) || k < len(added); k++ {
+			switch {
+			case k >= len(added):
+				changes = append(changes, &Change{Kind: Removed, Declaration: o.key, Member: removed[k].text, Old: removed[k].text, OldPos: removed[k].pos})
+			case k >= len(removed):
+				changes = append(changes, &Change{Kind: Added, Declaration: n.key, Member: added[k].text, New: added[k].text, NewPos: added[k].pos})
+			default:
+				changes = append(changes, &Change{Kind: Changed, Declaration: o.key, Member: removed[k].text, Old: removed[k].text, New: added[k].text, OldPos: removed[k].pos, NewPos: added[k].pos})
+			}
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i].text == b[j].text:
+			flush()
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+
+	return changes
+}
@@ -0,0 +1,178 @@
+package vcldiff
+
+import (
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse failed: %v", errs)
+	}
+
+	return program
+}
+
+func TestDiff(t *testing.T) {
+	testCases := map[string]struct {
+		old      string
+		new      string
+		expected []string
+	}{
+		"no changes": {
+			old:      `backend F_origin { .host = "example.com"; }`,
+			new:      `backend F_origin { .host = "example.com"; }`,
+			expected: []string{},
+		},
+		"formatting and comments": {
+			old: `backend F_origin { .host = "example.com"; .probe = { .url = "/"; } }
+sub vcl_recv { set req.url = "/"; }`,
+			new: `# the origin
+backend F_origin {
+	.host = "example.com"; # host
+	.probe = {
+		.url = "/";
+	}
+}
+
+sub vcl_recv {
+	// root
+	set req.url = "/";
+}`,
+			expected: []string{},
+		},
+		"declarations": {
+			old: `backend F_a {}
+backend F_b {}`,
+			new: `backend F_b {}
+acl local {}`,
+			expected: []string{
+				"- backend F_a",
+				"+ acl local",
+			},
+		},
+		"backend properties": {
+			old: `backend F_origin { .host = "example.com"; .port = "80"; }`,
+			new: `backend F_origin { .port = "443"; .ssl = true; }`,
+			expected: []string{
+				`- backend F_origin: .host = "example.com";`,
+				`~ backend F_origin: .port = "80"; -> .port = "443";`,
+				`+ backend F_origin: .ssl = true;`,
+			},
+		},
+		"director type": {
+			old: `director my_dir random { { .backend = F_a; .weight = 1; } }`,
+			new: `director my_dir hash { { .backend = F_a; .weight = 1; } }`,
+			expected: []string{
+				`~ director my_dir: director my_dir random -> director my_dir hash`,
+			},
+		},
+		"acl entries": {
+			old: `acl local { "localhost"; "10.0.0.0"/8; }`,
+			new: `acl local { "10.0.0.0"/8; !"10.0.0.1"; "localhost"; }`,
+			expected: []string{
+				`+ acl local: !"10.0.0.1";`,
+			},
+		},
+		"table keys": {
+			old: `table t { "a": "1", "b": "2", }`,
+			new: `table t { "b": "3", "c": "4", }`,
+			expected: []string{
+				`- table t: "a": "1",`,
+				`~ table t: "b": "2", -> "b": "3",`,
+				`+ table t: "c": "4",`,
+			},
+		},
+		"subroutine statements": {
+			old: `sub vcl_recv {
+	set req.http.A = "1";
+	error 403 "Forbidden";
+	return (lookup);
+}`,
+			new: `sub vcl_recv {
+	call check;
+	set req.http.A = "1";
+	error 404 "Not Found";
+	return (lookup);
+}`,
+			expected: []string{
+				`+ sub vcl_recv: call check;`,
+				`~ sub vcl_recv: error 403 "Forbidden"; -> error 404 "Not Found";`,
+			},
+		},
+		"removed statements": {
+			old: `sub vcl_recv {
+	set req.http.A = "1";
+	unset req.http.B;
+}`,
+			new: `sub vcl_recv {
+	unset req.http.B;
+}`,
+			expected: []string{
+				`- sub vcl_recv: set req.http.A = "1";`,
+			},
+		},
+		"split subroutines": {
+			old: `sub vcl_recv { set req.http.A = "1"; }
+sub vcl_recv { set req.http.B = "2"; }`,
+			new: `sub vcl_recv {
+	set req.http.A = "1";
+	set req.http.B = "2";
+}`,
+			expected: []string{},
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			changes, err := Diff(parse(t, tc.old), parse(t, tc.new))
+			if err != nil {
+				t.Fatalf("Diff failed: %v", err)
+			}
+
+			if len(changes) != len(tc.expected) {
+				t.Fatalf("changes length wrong, got:%v, want:%v", changes, tc.expected)
+			}
+
+			for i, c := range changes {
+				if c.String() != tc.expected[i] {
+					t.Fatalf("got:%s, want:%s", c, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiff_Positions(t *testing.T) {
+	old := parse(t, `backend F_origin {
+	.port = "80";
+}`)
+	new := parse(t, `# origin
+backend F_origin {
+	.port = "443";
+}`)
+
+	changes, err := Diff(old, new)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("changes length wrong, got:%d, want:%d", len(changes), 1)
+	}
+
+	if got := changes[0].OldPos.String(); got != "2:2" {
+		t.Fatalf("old position wrong, got:%s, want:%s", got, "2:2")
+	}
+
+	if got := changes[0].NewPos.String(); got != "3:2" {
+		t.Fatalf("new position wrong, got:%s, want:%s", got, "3:2")
+	}
+}
@@ -0,0 +1,189 @@
+package vcljson
+
+import (
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Node is a node of the AST in the JSON and YAML representation.
+// Kind is the name of the node type in the ast package such as "SetStatement" and only the fields of the kind are set.
+// Value is the literal for the literals and the identifiers, and the node of the expression for the statements.
+// Every node has pos except the ones which are generated.
+//
+//	Program              statements
+//	AssignStatement      name, value
+//	AssignFieldStatement name, value
+//	SetStatement         name, operator such as "=" or "+=", value
+//	UnsetStatement       name
+//	ReturnStatement      value, which is omitted for `return;`
+//	CallStatement        value
+//	CommentStatement     style ("#", "//" or "/*"), value
+//	ExpressionStatement  expression
+//	BlockStatement       statements
+//	BadStatement         end
+//	Identifier           value
+//	StringLiteral        value
+//	IntegerLiteral       value (number)
+//	BooleanLiteral       value (boolean)
+//	CIDRLiteral          value such as "\"10.0.0.0\"/8"
+//	PercentageLiteral    value such as "50%"
+//	RTimeLiteral         value such as "10s"
+//	PrefixExpression     operator, right
+//	InfixExpression      operator, left, right
+//	IfExpression         condition, consequence, alternative
+//	BlockExpression      keyword, labels, body
+//	CallExpression       function, arguments
+//	BadExpression
+type Node struct {
+	Kind string    `json:"kind" yaml:"kind"`
+	Pos  *Position `json:"pos,omitempty" yaml:"pos,omitempty"`
+	End  *Position `json:"end,omitempty" yaml:"end,omitempty"`
+
+	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Style    string      `json:"style,omitempty" yaml:"style,omitempty"`
+	Operator string      `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Keyword  string      `json:"keyword,omitempty" yaml:"keyword,omitempty"`
+	Labels   []string    `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	Left        *Node   `json:"left,omitempty" yaml:"left,omitempty"`
+	Right       *Node   `json:"right,omitempty" yaml:"right,omitempty"`
+	Expression  *Node   `json:"expression,omitempty" yaml:"expression,omitempty"`
+	Condition   *Node   `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Consequence *Node   `json:"consequence,omitempty" yaml:"consequence,omitempty"`
+	Alternative *Node   `json:"alternative,omitempty" yaml:"alternative,omitempty"`
+	Body        *Node   `json:"body,omitempty" yaml:"body,omitempty"`
+	Function    *Node   `json:"function,omitempty" yaml:"function,omitempty"`
+	Arguments   []*Node `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+	Statements  []*Node `json:"statements,omitempty" yaml:"statements,omitempty"`
+}
+
+// Position is the position of the node in the source
+type Position struct {
+	Offset int `json:"offset" yaml:"offset"`
+	Line   int `json:"line" yaml:"line"`
+	Column int `json:"column" yaml:"column"`
+}
+
+func position(pos token.Position) *Position {
+	if !pos.IsValid() {
+		return nil
+	}
+
+	return &Position{
+		Offset: pos.Offset,
+		Line:   pos.Line,
+		Column: pos.Column,
+	}
+}
+
+// commentStyles maps the token of the comment to the style
+var commentStyles = map[token.Type]string{
+	token.HASH:              "#",
+	token.COMMENTLINE:       "//",
+	token.LMULTICOMMENTLINE: "/*",
+}
+
+// Program returns the node of the program with all statements
+func Program(program *ast.Program) *Node {
+	return &Node{
+		Kind:       "Program",
+		Statements: statements(program.Statements),
+	}
+}
+
+func statements(stmts []ast.Statement) []*Node {
+	nodes := make([]*Node, 0, len(stmts))
+	for _, stmt := range stmts {
+		nodes = append(nodes, statement(stmt))
+	}
+
+	return nodes
+}
+
+func statement(stmt ast.Statement) *Node {
+	switch v := stmt.(type) {
+	case *ast.AssignStatement:
+		return &Node{Kind: "AssignStatement", Pos: position(v.Name.Token.Pos), Name: v.Name.Value, Value: expression(v.Value)}
+	case *ast.AssignFieldStatement:
+		return &Node{Kind: "AssignFieldStatement", Pos: position(v.Name.Token.Pos), Name: v.Name.Value, Value: expression(v.Value)}
+	case *ast.SetStatement:
+		operator := v.Operator
+		if operator == "" {
+			operator = "="
+		}
+
+		return &Node{Kind: "SetStatement", Pos: position(v.Token.Pos), Name: v.Name.Value, Operator: operator, Value: expression(v.Value)}
+	case *ast.UnsetStatement:
+		return &Node{Kind: "UnsetStatement", Pos: position(v.Token.Pos), Name: v.Name.Value}
+	case *ast.ReturnStatement:
+		node := &Node{Kind: "ReturnStatement", Pos: position(v.Token.Pos)}
+		if v.ReturnValue != nil {
+			node.Value = expression(v.ReturnValue)
+		}
+
+		return node
+	case *ast.CallStatement:
+		return &Node{Kind: "CallStatement", Pos: position(v.Token.Pos), Value: expression(v.CallValue)}
+	case *ast.CommentStatement:
+		return &Node{Kind: "CommentStatement", Pos: position(v.Token.Pos), Style: commentStyles[v.Token.Type], Value: v.Value}
+	case *ast.ExpressionStatement:
+		return &Node{Kind: "ExpressionStatement", Pos: position(v.Token.Pos), Expression: expression(v.Expression)}
+	case *ast.BlockStatement:
+		return block(v)
+	case *ast.BadStatement:
+		return &Node{Kind: "BadStatement", Pos: position(v.Token.Pos), End: position(v.End)}
+	}
+
+	return nil
+}
+
+func block(b *ast.BlockStatement) *Node {
+	if b == nil {
+		return nil
+	}
+
+	return &Node{
+		Kind:       "BlockStatement",
+		Pos:        position(b.Token.Pos),
+		End:        position(b.Rbrace),
+		Statements: statements(b.Statements),
+	}
+}
+
+func expression(expr ast.Expression) *Node {
+	switch v := expr.(type) {
+	case *ast.Identifier:
+		return &Node{Kind: "Identifier", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.StringLiteral:
+		return &Node{Kind: "StringLiteral", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.IntegerLiteral:
+		return &Node{Kind: "IntegerLiteral", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.BooleanLiteral:
+		return &Node{Kind: "BooleanLiteral", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.CIDRLiteral:
+		return &Node{Kind: "CIDRLiteral", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.PercentageLiteral:
+		return &Node{Kind: "PercentageLiteral", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.RTimeLiteral:
+		return &Node{Kind: "RTimeLiteral", Pos: position(v.Token.Pos), Value: v.Value}
+	case *ast.PrefixExpression:
+		return &Node{Kind: "PrefixExpression", Pos: position(v.Token.Pos), Operator: v.Operator, Right: expression(v.Right)}
+	case *ast.InfixExpression:
+		return &Node{Kind: "InfixExpression", Pos: position(v.Token.Pos), Operator: v.Operator, Left: expression(v.Left), Right: expression(v.Right)}
+	case *ast.IfExpression:
+		return &Node{Kind: "IfExpression", Pos: position(v.Token.Pos), Condition: expression(v.Condition), Consequence: block(v.Consequence), Alternative: block(v.Alternative)}
+	case *ast.BlockExpression:
+		return &Node{Kind: "BlockExpression", Pos: position(v.Token.Pos), Keyword: v.Token.Literal, Labels: v.Labels, Body: block(v.Blocks)}
+	case *ast.CallExpression:
+		args := make([]*Node, 0, len(v.Arguments))
+		for _, arg := range v.Arguments {
+			args = append(args, expression(arg))
+		}
+		return &Node{Kind: "CallExpression", Pos: position(v.Token.Pos), Function: expression(v.Function), Arguments: args}
+	case *ast.BadExpression:
+		return &Node{Kind: "BadExpression", Pos: position(v.Token.Pos)}
+	}
+
+	return nil
+}
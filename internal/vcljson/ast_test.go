@@ -0,0 +1,52 @@
+package vcljson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func TestProgram(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"set statement": {
+			input:    `sub vcl_recv { set req.url = "/" + req.url; }`,
+			expected: `{"kind":"Program","statements":[{"kind":"ExpressionStatement","pos":{"offset":0,"line":1,"column":1},"expression":{"kind":"BlockExpression","pos":{"offset":0,"line":1,"column":1},"keyword":"sub","labels":["vcl_recv"],"body":{"kind":"BlockStatement","pos":{"offset":13,"line":1,"column":14},"end":{"offset":44,"line":1,"column":45},"statements":[{"kind":"SetStatement","pos":{"offset":15,"line":1,"column":16},"name":"req.url","value":{"kind":"InfixExpression","pos":{"offset":33,"line":1,"column":34},"operator":"+","left":{"kind":"StringLiteral","pos":{"offset":29,"line":1,"column":30},"value":"/"},"right":{"kind":"Identifier","pos":{"offset":35,"line":1,"column":36},"value":"req.url"}},"operator":"="}]}}}]}`,
+		},
+		"compound set and return without action": {
+			input:    `sub f { set var.n += 1; return; }`,
+			expected: `{"kind":"Program","statements":[{"kind":"ExpressionStatement","pos":{"offset":0,"line":1,"column":1},"expression":{"kind":"BlockExpression","pos":{"offset":0,"line":1,"column":1},"keyword":"sub","labels":["f"],"body":{"kind":"BlockStatement","pos":{"offset":6,"line":1,"column":7},"end":{"offset":32,"line":1,"column":33},"statements":[{"kind":"SetStatement","pos":{"offset":8,"line":1,"column":9},"name":"var.n","value":{"kind":"IntegerLiteral","pos":{"offset":21,"line":1,"column":22},"value":1},"operator":"+="},{"kind":"ReturnStatement","pos":{"offset":24,"line":1,"column":25}}]}}}]}`,
+		},
+		"comment": {
+			input:    `# hello`,
+			expected: `{"kind":"Program","statements":[{"kind":"CommentStatement","pos":{"offset":0,"line":1,"column":1},"value":"hello","style":"#"}]}`,
+		},
+		"integer": {
+			input:    `x = 10;`,
+			expected: `{"kind":"Program","statements":[{"kind":"AssignStatement","pos":{"offset":0,"line":1,"column":1},"name":"x","value":{"kind":"IntegerLiteral","pos":{"offset":4,"line":1,"column":5},"value":10}}]}`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p := parser.NewParser(lexer.NewLexer(tc.input))
+			program := p.ParseProgram()
+			if errs := p.Errors(); len(errs) > 0 {
+				t.Fatalf("parse failed: %v", errs)
+			}
+
+			b, err := json.Marshal(Program(program))
+			if err != nil {
+				t.Fatalf("json.Marshal failed: %v", err)
+			}
+
+			if string(b) != tc.expected {
+				t.Fatalf("got:%s, want:%s", b, tc.expected)
+			}
+		})
+	}
+}
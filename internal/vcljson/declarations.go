@@ -0,0 +1,299 @@
+package vcljson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// Document is the declarative part of the program which is backends, directors, ACLs and tables.
+// The properties such as .host are keyed without the leading dot, and their values are
+//
+//	STRING               string
+//	INTEGER              number
+//	BOOL                 boolean
+//	RTIME such as 10s    string "10s"
+//	percentage           string "50%"
+//	identifier           string such as "F_origin"
+//	object such as probe object of the properties
+type Document struct {
+	Backends  []*Backend  `json:"backends" yaml:"backends"`
+	Directors []*Director `json:"directors" yaml:"directors"`
+	ACLs      []*ACL      `json:"acls" yaml:"acls"`
+	Tables    []*Table    `json:"tables" yaml:"tables"`
+}
+
+// Backend is a `backend` declaration
+type Backend struct {
+	Name       string                 `json:"name" yaml:"name"`
+	Properties map[string]interface{} `json:"properties" yaml:"properties"`
+}
+
+// Director is a `director` declaration such as `director my_dir random { ... }`
+type Director struct {
+	Name       string                 `json:"name" yaml:"name"`
+	Type       string                 `json:"type" yaml:"type"`
+	Properties map[string]interface{} `json:"properties" yaml:"properties"`
+
+	// Backends are the entries such as `{ .backend = F_origin; .weight = 1; }`
+	Backends []map[string]interface{} `json:"backends" yaml:"backends"`
+}
+
+// ACL is an `acl` declaration
+type ACL struct {
+	Name    string      `json:"name" yaml:"name"`
+	Entries []*ACLEntry `json:"entries" yaml:"entries"`
+}
+
+// ACLEntry is an entry of the ACL such as `!"10.0.0.0"/8;`
+type ACLEntry struct {
+	IP      string `json:"ip" yaml:"ip"`
+	Prefix  *int   `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Negated bool   `json:"negated,omitempty" yaml:"negated,omitempty"`
+}
+
+// Table is a `table` declaration.
+// Type is the type of the values such as "BOOL" and empty for STRING.
+type Table struct {
+	Name    string                 `json:"name" yaml:"name"`
+	Type    string                 `json:"type,omitempty" yaml:"type,omitempty"`
+	Entries map[string]interface{} `json:"entries" yaml:"entries"`
+}
+
+// Error is an error of the conversion
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the error message with the position
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Declarations returns the declarative part of the program.
+// Subroutines and the other statements are ignored, and the values which are not literals are reported as errors.
+func Declarations(program *ast.Program) (*Document, []error) {
+	c := &converter{errs: []error{}}
+	doc := &Document{
+		Backends:  []*Backend{},
+		Directors: []*Director{},
+		ACLs:      []*ACL{},
+		Tables:    []*Table{},
+	}
+
+	for _, stmt := range program.Statements {
+		es, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+
+		decl, ok := es.Expression.(*ast.BlockExpression)
+		if !ok || len(decl.Labels) == 0 {
+			continue
+		}
+
+		switch decl.Token.Type {
+		case token.BACKEND:
+			doc.Backends = append(doc.Backends, &Backend{
+				Name:       decl.Labels[0],
+				Properties: c.properties(body(decl)),
+			})
+		case token.DIRECTOR:
+			doc.Directors = append(doc.Directors, c.director(decl))
+		case token.ACL:
+			doc.ACLs = append(doc.ACLs, c.acl(decl))
+		case token.TABLE:
+			doc.Tables = append(doc.Tables, c.table(decl))
+		}
+	}
+
+	return doc, c.errs
+}
+
+func body(decl *ast.BlockExpression) []ast.Statement {
+	if decl.Blocks == nil {
+		return nil
+	}
+
+	return decl.Blocks.Statements
+}
+
+type converter struct {
+	errs []error
+}
+
+func (c *converter) errorf(pos token.Position, format string, args ...interface{}) {
+	c.errs = append(c.errs, &Error{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	})
+}
+
+// properties returns the assignments such as `.host = "example.com";` by the name without the dot
+func (c *converter) properties(stmts []ast.Statement) map[string]interface{} {
+	props := map[string]interface{}{}
+	for _, stmt := range stmts {
+		assign, ok := stmt.(*ast.AssignStatement)
+		if !ok {
+			continue
+		}
+
+		if value, ok := c.value(assign.Name, assign.Value); ok {
+			props[strings.TrimPrefix(assign.Name.Value, ".")] = value
+		}
+	}
+
+	return props
+}
+
+// value returns the literal as the JSON value
+func (c *converter) value(name *ast.Identifier, expr ast.Expression) (interface{}, bool) {
+	switch v := expr.(type) {
+	case *ast.StringLiteral:
+		return v.Value, true
+	case *ast.IntegerLiteral:
+		return v.Value, true
+	case *ast.BooleanLiteral:
+		return v.Value, true
+	case *ast.RTimeLiteral:
+		return v.Value, true
+	case *ast.PercentageLiteral:
+		return v.Value, true
+	case *ast.Identifier:
+		return v.Value, true
+	case *ast.BlockExpression:
+		return c.properties(body(v)), true
+	}
+
+	c.errorf(name.Token.Pos, "value of %s must be a literal", name.Value)
+	return nil, false
+}
+
+func (c *converter) director(decl *ast.BlockExpression) *Director {
+	d := &Director{
+		Name:       decl.Labels[0],
+		Properties: c.properties(body(decl)),
+		Backends:   []map[string]interface{}{},
+	}
+
+	if len(decl.Labels) > 1 {
+		d.Type = decl.Labels[1]
+	}
+
+	for _, stmt := range body(decl) {
+		es, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+
+		if entry, ok := es.Expression.(*ast.BlockExpression); ok && entry.Token.Type == token.LBRACE {
+			d.Backends = append(d.Backends, c.properties(body(entry)))
+		}
+	}
+
+	return d
+}
+
+func (c *converter) acl(decl *ast.BlockExpression) *ACL {
+	acl := &ACL{
+		Name:    decl.Labels[0],
+		Entries: []*ACLEntry{},
+	}
+
+	for _, stmt := range body(decl) {
+		es, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+
+		if entry, ok := c.aclEntry(es.Expression); ok {
+			acl.Entries = append(acl.Entries, entry)
+		}
+	}
+
+	return acl
+}
+
+// aclEntry returns the entry such as "localhost", "10.0.0.0"/8 or !"10.0.0.1"
+func (c *converter) aclEntry(expr ast.Expression) (*ACLEntry, bool) {
+	switch v := expr.(type) {
+	case *ast.StringLiteral:
+		return &ACLEntry{IP: v.Value}, true
+	case *ast.CIDRLiteral:
+		// the literal is such as "10.0.0.0"/8
+		i := strings.LastIndex(v.Value, "\"/")
+		if i < 0 {
+			c.errorf(v.Token.Pos, "invalid ACL entry %s", v.Value)
+			return nil, false
+		}
+
+		prefix, err := strconv.Atoi(v.Value[i+2:])
+		if err != nil {
+			c.errorf(v.Token.Pos, "invalid ACL entry %s", v.Value)
+			return nil, false
+		}
+
+		return &ACLEntry{IP: strings.TrimPrefix(v.Value[:i], "\""), Prefix: &prefix}, true
+	case *ast.PrefixExpression:
+		if v.Operator == token.BANG {
+			entry, ok := c.aclEntry(v.Right)
+			if ok {
+				entry.Negated = true
+			}
+			return entry, ok
+		}
+	}
+
+	if pos := exprPos(expr); pos.IsValid() {
+		c.errorf(pos, "ACL entry must be a string or CIDR")
+	}
+
+	return nil, false
+}
+
+func exprPos(expr ast.Expression) token.Position {
+	switch v := expr.(type) {
+	case *ast.Identifier:
+		return v.Token.Pos
+	case *ast.IntegerLiteral:
+		return v.Token.Pos
+	case *ast.BooleanLiteral:
+		return v.Token.Pos
+	case *ast.PrefixExpression:
+		return v.Token.Pos
+	case *ast.InfixExpression:
+		return v.Token.Pos
+	case *ast.CallExpression:
+		return v.Token.Pos
+	}
+
+	return token.Position{}
+}
+
+func (c *converter) table(decl *ast.BlockExpression) *Table {
+	t := &Table{
+		Name:    decl.Labels[0],
+		Entries: map[string]interface{}{},
+	}
+
+	if len(decl.Labels) > 1 {
+		t.Type = decl.Labels[1]
+	}
+
+	for _, stmt := range body(decl) {
+		field, ok := stmt.(*ast.AssignFieldStatement)
+		if !ok {
+			continue
+		}
+
+		if value, ok := c.value(field.Name, field.Value); ok {
+			t.Entries[field.Name.Value] = value
+		}
+	}
+
+	return t
+}
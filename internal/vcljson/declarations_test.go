@@ -0,0 +1,122 @@
+package vcljson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+)
+
+func TestDeclarations(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"backend": {
+			input: `backend F_origin {
+	.host = "example.com";
+	.port = "443";
+	.max_connections = 200;
+	.ssl = true;
+	.probe = {
+		.url = "/health";
+		.timeout = 5s;
+	}
+}`,
+			expected: `{"backends":[{"name":"F_origin","properties":{"host":"example.com","max_connections":200,"port":"443","probe":{"timeout":"5s","url":"/health"},"ssl":true}}],"directors":[],"acls":[],"tables":[]}`,
+		},
+		"director": {
+			input: `director my_dir random {
+	.quorum = 50%;
+	{ .backend = F_a; .weight = 1; }
+	{ .backend = F_b; .weight = 2; }
+}`,
+			expected: `{"backends":[],"directors":[{"name":"my_dir","type":"random","properties":{"quorum":"50%"},"backends":[{"backend":"F_a","weight":1},{"backend":"F_b","weight":2}]}],"acls":[],"tables":[]}`,
+		},
+		"acl": {
+			input: `acl local {
+	"localhost";
+	!"10.0.0.1";
+	"192.168.0.0"/24;
+	!"10.0.0.0"/8;
+}`,
+			expected: `{"backends":[],"directors":[],"acls":[{"name":"local","entries":[{"ip":"localhost"},{"ip":"10.0.0.1","negated":true},{"ip":"192.168.0.0","prefix":24},{"ip":"10.0.0.0","prefix":8,"negated":true}]}],"tables":[]}`,
+		},
+		"table": {
+			input: `table flags BOOL {
+	"a": true,
+	"b": false,
+}
+table redirects {
+	"/old": "/new",
+}`,
+			expected: `{"backends":[],"directors":[],"acls":[],"tables":[{"name":"flags","type":"BOOL","entries":{"a":true,"b":false}},{"name":"redirects","entries":{"/old":"/new"}}]}`,
+		},
+		"subroutines are ignored": {
+			input: `sub vcl_recv {
+	set req.url = "/";
+}`,
+			expected: `{"backends":[],"directors":[],"acls":[],"tables":[]}`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p := parser.NewParser(lexer.NewLexer(tc.input))
+			program := p.ParseProgram()
+			if errs := p.Errors(); len(errs) > 0 {
+				t.Fatalf("parse failed: %v", errs)
+			}
+
+			doc, errs := Declarations(program)
+			if len(errs) > 0 {
+				t.Fatalf("Declarations failed: %v", errs)
+			}
+
+			b, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatalf("json.Marshal failed: %v", err)
+			}
+
+			if string(b) != tc.expected {
+				t.Fatalf("got:%s, want:%s", b, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDeclarations_Error(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"not a literal": {
+			input:    `backend F_origin { .host = "example" + ".com"; }`,
+			expected: "1:20: value of .host must be a literal",
+		},
+		"acl entry": {
+			input:    `acl local { 1; }`,
+			expected: "1:13: ACL entry must be a string or CIDR",
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p := parser.NewParser(lexer.NewLexer(tc.input))
+			program := p.ParseProgram()
+			if errs := p.Errors(); len(errs) > 0 {
+				t.Fatalf("parse failed: %v", errs)
+			}
+
+			_, errs := Declarations(program)
+			if len(errs) != 1 {
+				t.Fatalf("errors length wrong, got:%d, want:%d", len(errs), 1)
+			}
+
+			if errs[0].Error() != tc.expected {
+				t.Fatalf("got:%v, want:%v", errs[0], tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package vcljson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/token"
+)
+
+// propertyKinds are the literal kinds of the properties whose values are written as strings in the document.
+// The string values of the other properties are VCL strings.
+var propertyKinds = map[string]token.Type{
+	"connect_timeout":       token.RTIME,
+	"first_byte_timeout":    token.RTIME,
+	"between_bytes_timeout": token.RTIME,
+	"timeout":               token.RTIME,
+	"interval":              token.RTIME,
+	"quorum":                token.PERCENTAGE,
+	"port":                  token.STRING,
+	"backend":               token.IDENT,
+	"probe":                 token.IDENT,
+}
+
+// tableKinds are the literal kinds of the table values by the table type
+var tableKinds = map[string]token.Type{
+	"":        token.STRING,
+	"STRING":  token.STRING,
+	"IP":      token.STRING,
+	"BOOL":    token.TRUE,
+	"INTEGER": token.INT,
+	"RTIME":   token.RTIME,
+	"BACKEND": token.IDENT,
+	"ACL":     token.IDENT,
+}
+
+// GenerateError is an error of the generation at the path of the document such as "backends[0].properties.port"
+type GenerateError struct {
+	Path string
+	Msg  string
+}
+
+// Error returns the error message with the path
+func (e *GenerateError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// Generate returns the program which declares the backends, directors, ACLs and tables of the document for the dialect.
+// The properties are sorted by the name, and the values are converted as the inverse of Declarations.
+// Directors and tables are reported as errors for Varnish since they are not a part of the language.
+func Generate(doc *Document, d *dialect.Dialect) (*ast.Program, []error) {
+	g := &generator{errs: []error{}}
+	program := &ast.Program{Statements: []ast.Statement{}}
+
+	for i, b := range doc.Backends {
+		path := fmt.Sprintf("backends[%d]", i)
+		program.Statements = append(program.Statements, g.declaration(path, token.BACKEND, "backend", []string{b.Name}, g.properties(path+".properties", b.Properties)))
+	}
+
+	for i, dir := range doc.Directors {
+		path := fmt.Sprintf("directors[%d]", i)
+		if d == dialect.Varnish {
+			g.errorf(path, "directors are not supported by %s, use the directors VMOD in vcl_init", d.Name)
+			continue
+		}
+
+		program.Statements = append(program.Statements, g.director(path, dir))
+	}
+
+	for i, acl := range doc.ACLs {
+		path := fmt.Sprintf("acls[%d]", i)
+		program.Statements = append(program.Statements, g.declaration(path, token.ACL, "acl", []string{acl.Name}, g.aclEntries(path+".entries", acl.Entries)))
+	}
+
+	for i, t := range doc.Tables {
+		path := fmt.Sprintf("tables[%d]", i)
+		if d == dialect.Varnish {
+			g.errorf(path, "tables are not supported by %s", d.Name)
+			continue
+		}
+
+		program.Statements = append(program.Statements, g.table(path, t))
+	}
+
+	return program, g.errs
+}
+
+type generator struct {
+	errs []error
+}
+
+func (g *generator) errorf(path string, format string, args ...interface{}) {
+	g.errs = append(g.errs, &GenerateError{
+		Path: path,
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+// declaration returns the statement of the declaration such as `backend F_origin { ... }`
+func (g *generator) declaration(path string, typ token.Type, keyword string, labels []string, stmts []ast.Statement) ast.Statement {
+	for _, label := range labels {
+		if !isName(label) {
+			g.errorf(path+".name", "invalid name %q", label)
+		}
+	}
+
+	expr := &ast.BlockExpression{
+		Token:  token.Token{Type: typ, Literal: keyword},
+		Labels: labels,
+		Blocks: &ast.BlockStatement{Token: token.Token{Type: token.LBRACE, Literal: "{"}, Statements: stmts},
+	}
+
+	return &ast.ExpressionStatement{Token: expr.Token, Expression: expr}
+}
+
+// object returns the object such as `{ .url = "/"; }`
+func object(stmts []ast.Statement) *ast.BlockExpression {
+	tok := token.Token{Type: token.LBRACE, Literal: "{"}
+	return &ast.BlockExpression{
+		Token:  tok,
+		Blocks: &ast.BlockStatement{Token: tok, Statements: stmts},
+	}
+}
+
+// properties returns the assignments such as `.host = "example.com";` sorted by the name
+func (g *generator) properties(path string, props map[string]interface{}) []ast.Statement {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stmts := make([]ast.Statement, 0, len(props))
+	for _, name := range names {
+		p := path + "." + name
+		if !lexesAs("."+name, token.IDENT) || strings.Contains(name, ".") {
+			g.errorf(p, "invalid property name %q", name)
+			continue
+		}
+
+		var value ast.Expression
+		if obj, ok := props[name].(map[string]interface{}); ok {
+			value = object(g.properties(p, obj))
+		} else {
+			value = g.literal(p, props[name], propertyKinds[name])
+		}
+
+		if value == nil {
+			continue
+		}
+
+		stmts = append(stmts, &ast.AssignStatement{
+			Token: token.Token{Type: token.ASSIGN, Literal: "="},
+			Name:  &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "." + name}, Value: "." + name},
+			Value: value,
+		})
+	}
+
+	return stmts
+}
+
+// literal returns the literal of the value.
+// Strings are read as the kind such as token.RTIME, and numbers are written as strings for token.STRING such as `.port = "443";`.
+func (g *generator) literal(path string, v interface{}, kind token.Type) ast.Expression {
+	if kind == token.STRING {
+		switch n := v.(type) {
+		case int, int64, uint64:
+			v = fmt.Sprint(n)
+		case float64:
+			v = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return g.stringLiteral(path, v, kind)
+	case bool:
+		tok := token.Token{Type: token.FALSE, Literal: "false"}
+		if v {
+			tok = token.Token{Type: token.TRUE, Literal: "true"}
+		}
+		return &ast.BooleanLiteral{Token: tok, Value: v}
+	case int:
+		return integer(int64(v))
+	case int64:
+		return integer(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			g.errorf(path, "integer %d overflows", v)
+			return nil
+		}
+		return integer(int64(v))
+	case float64:
+		// JSON numbers are decoded as float64
+		if v != math.Trunc(v) || v > math.MaxInt64 || v < math.MinInt64 {
+			g.errorf(path, "number %v must be an integer", v)
+			return nil
+		}
+		return integer(int64(v))
+	}
+
+	g.errorf(path, "unsupported value %v", v)
+	return nil
+}
+
+func (g *generator) stringLiteral(path, s string, kind token.Type) ast.Expression {
+	switch kind {
+	case token.RTIME:
+		if !lexesAs(s, token.RTIME) {
+			g.errorf(path, "%q must be a relative time such as \"5s\"", s)
+			return nil
+		}
+		return &ast.RTimeLiteral{Token: token.Token{Type: token.RTIME, Literal: s}, Value: s}
+	case token.PERCENTAGE:
+		if !lexesAs(s, token.PERCENTAGE) {
+			g.errorf(path, "%q must be a percentage such as \"50%%\"", s)
+			return nil
+		}
+		return &ast.PercentageLiteral{Token: token.Token{Type: token.PERCENTAGE, Literal: s}, Value: s}
+	case token.IDENT:
+		if !isName(s) {
+			g.errorf(path, "%q must be a name", s)
+			return nil
+		}
+		return &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: s}, Value: s}
+	}
+
+	if strings.ContainsAny(s, "\"\n") {
+		g.errorf(path, "string %q cannot contain double quotes or newlines", s)
+		return nil
+	}
+
+	return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: s}, Value: s}
+}
+
+func integer(v int64) *ast.IntegerLiteral {
+	s := strconv.FormatInt(v, 10)
+	return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: s}, Value: v}
+}
+
+// lexesAs reports whether the string is a single token of the type
+func lexesAs(s string, typ token.Type) bool {
+	l := lexer.NewLexer(s)
+	return l.NextToken().Type == typ && l.NextToken().Type == token.EOF
+}
+
+// isName reports whether the string is a name such as "F_origin" which is not a keyword
+func isName(s string) bool {
+	return lexesAs(s, token.IDENT) && !strings.Contains(s, ".")
+}
+
+func (g *generator) director(path string, dir *Director) ast.Statement {
+	labels := []string{dir.Name}
+	if dir.Type != "" {
+		labels = append(labels, dir.Type)
+	}
+
+	stmts := g.properties(path+".properties", dir.Properties)
+	for i, backend := range dir.Backends {
+		entry := object(g.properties(fmt.Sprintf("%s.backends[%d]", path, i), backend))
+		stmts = append(stmts, &ast.ExpressionStatement{Token: entry.Token, Expression: entry})
+	}
+
+	return g.declaration(path, token.DIRECTOR, "director", labels, stmts)
+}
+
+// aclEntries returns the entries such as `!"10.0.0.0"/8;`
+func (g *generator) aclEntries(path string, entries []*ACLEntry) []ast.Statement {
+	stmts := make([]ast.Statement, 0, len(entries))
+	for i, entry := range entries {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		if entry.IP == "" || strings.ContainsAny(entry.IP, "\"\n/;") {
+			g.errorf(p+".ip", "invalid IP %q", entry.IP)
+			continue
+		}
+
+		var expr ast.Expression = &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: entry.IP}, Value: entry.IP}
+		if entry.Prefix != nil {
+			if *entry.Prefix < 0 || *entry.Prefix > 128 {
+				g.errorf(p+".prefix", "prefix %d is out of range", *entry.Prefix)
+				continue
+			}
+
+			cidr := fmt.Sprintf("%q/%d", entry.IP, *entry.Prefix)
+			expr = &ast.CIDRLiteral{Token: token.Token{Type: token.CIDR, Literal: cidr}, Value: cidr}
+		}
+
+		if entry.Negated {
+			expr = &ast.PrefixExpression{Token: token.Token{Type: token.BANG, Literal: "!"}, Operator: "!", Right: expr}
+		}
+
+		stmts = append(stmts, &ast.ExpressionStatement{Token: token.Token{Type: token.STRING, Literal: entry.IP}, Expression: expr})
+	}
+
+	return stmts
+}
+
+func (g *generator) table(path string, t *Table) ast.Statement {
+	kind, ok := tableKinds[t.Type]
+	if !ok {
+		g.errorf(path+".type", "unsupported table type %q", t.Type)
+	}
+
+	keys := make([]string, 0, len(t.Entries))
+	for key := range t.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stmts := make([]ast.Statement, 0, len(t.Entries))
+	for _, key := range keys {
+		p := fmt.Sprintf("%s.entries[%q]", path, key)
+		if strings.ContainsAny(key, "\"\n") {
+			g.errorf(p, "key cannot contain double quotes or newlines")
+			continue
+		}
+
+		value := g.tableValue(p, t.Entries[key], kind)
+		if value == nil {
+			continue
+		}
+
+		stmts = append(stmts, &ast.AssignFieldStatement{
+			Token: token.Token{Type: token.COLON, Literal: ":"},
+			Name:  &ast.Identifier{Token: token.Token{Type: token.STRING, Literal: key}, Value: key},
+			Value: value,
+		})
+	}
+
+	labels := []string{t.Name}
+	if t.Type != "" {
+		labels = append(labels, t.Type)
+	}
+
+	return g.declaration(path, token.TABLE, "table", labels, stmts)
+}
+
+// tableValue returns the value which matches the type of the table
+func (g *generator) tableValue(path string, v interface{}, kind token.Type) ast.Expression {
+	switch kind {
+	case token.TRUE:
+		if _, ok := v.(bool); !ok {
+			g.errorf(path, "value must be a boolean")
+			return nil
+		}
+	case token.INT:
+		switch v.(type) {
+		case int, int64, uint64, float64:
+		default:
+			g.errorf(path, "value must be an integer")
+			return nil
+		}
+	default:
+		if _, ok := v.(string); !ok {
+			g.errorf(path, "value must be a string")
+			return nil
+		}
+	}
+
+	return g.literal(path, v, kind)
+}
@@ -0,0 +1,225 @@
+package vcljson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/dialect"
+	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
+	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/printer"
+)
+
+func TestGenerate(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"backend": {
+			input: `{"backends":[{"name":"F_origin","properties":{"host":"example.com","port":443,"ssl":true,"max_connections":200,"connect_timeout":"1s","probe":{"url":"/health","interval":"10s"}}}]}`,
+			expected: `backend F_origin {
+	.connect_timeout = 1s;
+	.host = "example.com";
+	.max_connections = 200;
+	.port = "443";
+	.probe = {
+		.interval = 10s;
+		.url = "/health";
+	}
+	.ssl = true;
+}
+`,
+		},
+		"director": {
+			input: `{"directors":[{"name":"my_dir","type":"random","properties":{"quorum":"50%"},"backends":[{"backend":"F_a","weight":1}]}]}`,
+			expected: `director my_dir random {
+	.quorum = 50%;
+	{
+		.backend = F_a;
+		.weight = 1;
+	}
+}
+`,
+		},
+		"acl": {
+			input: `{"acls":[{"name":"local","entries":[{"ip":"localhost"},{"ip":"10.0.0.1","negated":true},{"ip":"192.168.0.0","prefix":24}]}]}`,
+			expected: `acl local {
+	"localhost";
+	!"10.0.0.1";
+	"192.168.0.0"/24;
+}
+`,
+		},
+		"tables": {
+			input: `{"tables":[{"name":"redirects","entries":{"/old":"/new","/a":"b"}},{"name":"flags","type":"BOOL","entries":{"a":true}}]}`,
+			expected: `table redirects {
+	"/a": "b",
+	"/old": "/new",
+}
+
+table flags BOOL {
+	"a": true,
+}
+`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			doc := &Document{}
+			if err := json.Unmarshal([]byte(tc.input), doc); err != nil {
+				t.Fatalf("json.Unmarshal failed: %v", err)
+			}
+
+			program, errs := Generate(doc, dialect.Fastly)
+			if len(errs) > 0 {
+				t.Fatalf("Generate failed: %v", errs)
+			}
+
+			got, err := printer.Print(program)
+			if err != nil {
+				t.Fatalf("Print failed: %v", err)
+			}
+
+			if got != tc.expected {
+				t.Fatalf("got:%s, want:%s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGenerate_RoundTrip(t *testing.T) {
+	input := `backend F_origin {
+	.host = "example.com";
+	.probe = {
+		.timeout = 5s;
+	}
+}
+
+director my_dir random {
+	.quorum = 50%;
+	{
+		.backend = F_origin;
+		.weight = 1;
+	}
+}
+
+acl local {
+	!"10.0.0.0"/8;
+}
+
+table ttls RTIME {
+	"a": 10s,
+}
+`
+
+	p := parser.NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse failed: %v", errs)
+	}
+
+	doc, errs := Declarations(program)
+	if len(errs) > 0 {
+		t.Fatalf("Declarations failed: %v", errs)
+	}
+
+	// the YAML decoder is used as the document of the users
+	b, err := yaml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("yaml.Marshal failed: %v", err)
+	}
+
+	decoded := &Document{}
+	if err := yaml.Unmarshal(b, decoded); err != nil {
+		t.Fatalf("yaml.Unmarshal failed: %v", err)
+	}
+
+	generated, errs := Generate(decoded, dialect.Fastly)
+	if len(errs) > 0 {
+		t.Fatalf("Generate failed: %v", errs)
+	}
+
+	got, err := printer.Print(generated)
+	if err != nil {
+		t.Fatalf("Print failed: %v", err)
+	}
+
+	if got != input {
+		t.Fatalf("got:%s, want:%s", got, input)
+	}
+}
+
+func TestGenerate_Error(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		dialect  *dialect.Dialect
+		expected string
+	}{
+		"invalid name": {
+			input:    `{"backends":[{"name":"F origin"}]}`,
+			dialect:  dialect.Fastly,
+			expected: `backends[0].name: invalid name "F origin"`,
+		},
+		"invalid relative time": {
+			input:    `{"backends":[{"name":"F_origin","properties":{"connect_timeout":"1 second"}}]}`,
+			dialect:  dialect.Fastly,
+			expected: `backends[0].properties.connect_timeout: "1 second" must be a relative time such as "5s"`,
+		},
+		"double quotes": {
+			input:    `{"backends":[{"name":"F_origin","properties":{"host":"a\"b"}}]}`,
+			dialect:  dialect.Fastly,
+			expected: `backends[0].properties.host: string "a\"b" cannot contain double quotes or newlines`,
+		},
+		"float": {
+			input:    `{"backends":[{"name":"F_origin","properties":{"weight":1.5}}]}`,
+			dialect:  dialect.Fastly,
+			expected: `backends[0].properties.weight: number 1.5 must be an integer`,
+		},
+		"prefix": {
+			input:    `{"acls":[{"name":"local","entries":[{"ip":"10.0.0.0","prefix":200}]}]}`,
+			dialect:  dialect.Fastly,
+			expected: `acls[0].entries[0].prefix: prefix 200 is out of range`,
+		},
+		"table value": {
+			input:    `{"tables":[{"name":"flags","type":"BOOL","entries":{"a":"yes"}}]}`,
+			dialect:  dialect.Fastly,
+			expected: `tables[0].entries["a"]: value must be a boolean`,
+		},
+		"table type": {
+			input:    `{"tables":[{"name":"t","type":"MAP","entries":{}}]}`,
+			dialect:  dialect.Fastly,
+			expected: `tables[0].type: unsupported table type "MAP"`,
+		},
+		"varnish director": {
+			input:    `{"directors":[{"name":"my_dir","type":"random"}]}`,
+			dialect:  dialect.Varnish,
+			expected: `directors[0]: directors are not supported by varnish, use the directors VMOD in vcl_init`,
+		},
+		"varnish table": {
+			input:    `{"tables":[{"name":"t"}]}`,
+			dialect:  dialect.Varnish,
+			expected: `tables[0]: tables are not supported by varnish`,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			doc := &Document{}
+			if err := json.Unmarshal([]byte(tc.input), doc); err != nil {
+				t.Fatalf("json.Unmarshal failed: %v", err)
+			}
+
+			_, errs := Generate(doc, tc.dialect)
+			if len(errs) != 1 {
+				t.Fatalf("errors length wrong, got:%v, want:%d", errs, 1)
+			}
+
+			if errs[0].Error() != tc.expected {
+				t.Fatalf("got:%v, want:%v", errs[0], tc.expected)
+			}
+		})
+	}
+}
@@ -1,16 +1,78 @@
 package vcl
 
 import (
+	"errors"
+	"os"
+
+	"github.com/KeisukeYamashita/go-vcl/internal/ast"
 	"github.com/KeisukeYamashita/go-vcl/internal/decoder"
 	"github.com/KeisukeYamashita/go-vcl/internal/lexer"
 	"github.com/KeisukeYamashita/go-vcl/internal/parser"
+	"github.com/KeisukeYamashita/go-vcl/internal/schema"
 )
 
+// DecodeError is an error of decoding with the Go field path, the VCL block and the position
+type DecodeError = decoder.DecodeError
+
+// Unmarshaler is implemented by the types which decode themselves from *Attribute or *Block
+type Unmarshaler = decoder.Unmarshaler
+
+// Validator is implemented by the structs which validate themselves after they are decoded
+type Validator = decoder.Validator
+
+// Attribute is an attribute such as `.port = "80";` which is passed to Unmarshaler
+type Attribute = schema.Attribute
+
+// Block is a block such as `backend F_origin { ... }` which is passed to Unmarshaler
+type Block = schema.Block
+
+// BodyContent is the content of a body which can be decoded by the remain fields
+type BodyContent = schema.BodyContent
+
+// Statement is a statement of the AST which can be decoded by the remain and body fields
+type Statement = ast.Statement
+
+// BlockStatement is the body of a block such as a subroutine which can be decoded by the body fields
+type BlockStatement = ast.BlockStatement
+
+// Option is an option of Decode
+type Option = decoder.Option
+
+// DisallowUnknownFields makes the attributes, the block types, the labels, the flats, the entries and the other statements which are not in the struct errors
+func DisallowUnknownFields() Option {
+	return decoder.DisallowUnknownFields()
+}
+
 // Decode ...
-func Decode(bs []byte, val interface{}) []error {
+func Decode(bs []byte, val interface{}, opts ...Option) []error {
+	_, errs := decode(bs, val, opts)
+	return errs
+}
+
+// DecodeAs decodes the source into a new value of T which is a struct or a map.
+// The syntax errors of the parser and the decoding errors are joined into one error by errors.Join.
+func DecodeAs[T any](src []byte, opts ...Option) (T, error) {
+	var val T
+	syntaxErrs, errs := decode(src, &val, opts)
+	return val, errors.Join(append(syntaxErrs, errs...)...)
+}
+
+// DecodeFile reads the file and decodes it into a new value of T like DecodeAs
+func DecodeFile[T any](path string, opts ...Option) (T, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return DecodeAs[T](src, opts...)
+}
+
+// decode returns the syntax errors of the parser and the errors of decoding
+func decode(bs []byte, val interface{}, opts []Option) ([]error, []error) {
 	p := getParser(bs)
 	prog := p.ParseProgram()
-	return decoder.Decode(prog, val)
+	return p.Errors(), decoder.Decode(prog, val, append([]Option{decoder.Source(bs)}, opts...)...)
 }
 
 func getParser(bs []byte) *parser.Parser {
@@ -1,6 +1,9 @@
 package vcl
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -40,3 +43,187 @@ func TestDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestDecode_Body(t *testing.T) {
+	type Sub struct {
+		Name   string `vcl:"name,label"`
+		Source string `vcl:",body"`
+	}
+
+	type Root struct {
+		Subs []*Sub `vcl:"sub,block"`
+	}
+
+	var r Root
+	if errs := Decode([]byte("sub vcl_recv {\n\treturn(lookup);\n}"), &r); len(errs) > 0 {
+		t.Fatalf("decode failed with error: %v", errs)
+	}
+
+	if got, want := r.Subs[0].Source, "\n\treturn(lookup);\n"; got != want {
+		t.Fatalf("decode got wrong source, got:%q, want:%q", got, want)
+	}
+}
+
+func TestDecodeAs(t *testing.T) {
+	type Backend struct {
+		Name string `vcl:"name,label"`
+		Port int    `vcl:".port"`
+	}
+
+	type Root struct {
+		Backends map[string]*Backend `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected Root
+		errs     int
+	}{
+		"with valid": {
+			"backend F_origin {\n\t.port = \"443\";\n}",
+			Root{Backends: map[string]*Backend{"F_origin": {Name: "F_origin", Port: 443}}},
+			0,
+		},
+		"with errors": {
+			"backend F_origin {\n\t.port = \"https\";\n}\nbackend F_origin {}",
+			Root{Backends: map[string]*Backend{"F_origin": {Name: "F_origin"}}},
+			2,
+		},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, err := DecodeAs[Root]([]byte(tc.input))
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("decode got wrong value, got:%+v, want:%+v", got, tc.expected)
+			}
+
+			if tc.errs == 0 {
+				if err != nil {
+					t.Fatalf("decode failed with error: %v", err)
+				}
+				return
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != tc.errs {
+				t.Fatalf("errors are not joined, got:%v, want:%d errors", err, tc.errs)
+			}
+
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("error is not DecodeError, got:%T", err)
+			}
+		})
+	}
+}
+
+func TestDecodeAs_SyntaxError(t *testing.T) {
+	type Backend struct {
+		Name string `vcl:"name,label"`
+		Host string `vcl:".host"`
+	}
+
+	type Root struct {
+		Backends []*Backend `vcl:"backend,block"`
+	}
+
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"with missing brace":  {`backend a { .host = "x" `, "1:25: expected }, got EOF"},
+		"with stray brackets": {`backend a { .host = "x"; } }}} )`, "1:28: unexpected \"}\"\n1:29: unexpected \"}\"\n1:30: unexpected \"}\"\n1:32: unexpected \")\""},
+	}
+
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := DecodeAs[Root]([]byte(tc.input))
+			if err == nil {
+				t.Fatalf("decode should fail with syntax error")
+			}
+
+			if err.Error() != tc.expected {
+				t.Fatalf("got:%v, want:%v", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeFile(t *testing.T) {
+	type ACL struct {
+		Type      string   `vcl:"type,label"`
+		Endpoints []string `vcl:",flat"`
+	}
+
+	type Root struct {
+		ACLs []*ACL `vcl:"acl,block"`
+	}
+
+	path := filepath.Join(t.TempDir(), "default.vcl")
+	if err := os.WriteFile(path, []byte("acl local {\n\t\"localhost\";\n}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DecodeFile[Root](path)
+	if err != nil {
+		t.Fatalf("decode failed with error: %v", err)
+	}
+
+	expected := Root{ACLs: []*ACL{{Type: "local", Endpoints: []string{"localhost"}}}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("decode got wrong value, got:%+v, want:%+v", got, expected)
+	}
+
+	if _, err := DecodeFile[Root](filepath.Join(t.TempDir(), "missing.vcl")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("decode should fail with not exist, got:%v", err)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	type Backend struct {
+		Name string `vcl:"name,label"`
+		Host string `vcl:".host"`
+	}
+
+	type Entry struct {
+		Backend string `vcl:".backend"`
+		Weight  int64  `vcl:".weight"`
+	}
+
+	type Director struct {
+		Name    string   `vcl:"name,label"`
+		Type    string   `vcl:"type,label"`
+		Entries []*Entry `vcl:",entries"`
+	}
+
+	type ACL struct {
+		Name      string   `vcl:"name,label"`
+		Endpoints []string `vcl:"endpoints,flat"`
+	}
+
+	type Root struct {
+		Backends  []*Backend  `vcl:"backend,block"`
+		Directors []*Director `vcl:"director,block"`
+		ACLs      []*ACL      `vcl:"acl,block"`
+		Comments  []string    `vcl:",comment"`
+	}
+
+	for _, seed := range []string{
+		`acl local { "localhost"; "192.168.0.0"/24; }`,
+		`backend F_origin { .host = "example.com"; .port = "443"; }`,
+		`director my_dir random { .quorum = 50%; { .backend = F_origin; .weight = 1; } }`,
+		`table redirects { "/old": "/new", }`,
+		`# comment
+sub vcl_recv { set req.http.X = "1"; }`,
+		`acl { x = 1 }`,
+		`x = foo(1)`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		Decode(input, &Root{})
+		Decode(input, &map[string]interface{}{})
+	})
+}